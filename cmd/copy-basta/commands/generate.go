@@ -8,7 +8,7 @@ import (
 	"copy-basta/internal/common"
 )
 
-func Generate(globals func() error) *cobra.Command {
+func Generate(globals func() error, version string) *cobra.Command {
 
 	const (
 		commandUse         = "generate"
@@ -27,15 +27,27 @@ func Generate(globals func() error) *cobra.Command {
 		flagInput            = "input"
 		flagDescriptionInput = "path to the YAML file with the variables to use in the templates"
 
+		flagPreset            = "preset"
+		flagDescriptionPreset = "name of the template preset to apply, only the variables it doesn't set are asked"
+
+		flagLang            = "lang"
+		flagDescriptionLang = "language of the prompts (en, pt_BR...), defaults to the LC_ALL or LANG locale, then to en"
+
 		flagOverwrite            = "overwrite"
 		flagDescriptionOverwrite = "Allow overriding files in an existing destination directory"
+
+		flagSkipVersionCheck            = "skip-version-check"
+		flagDescriptionSkipVersionCheck = "skip the check of the minimum cli version required by the template (for snapshot builds)"
 	)
 
 	var src string
 	var dest string
 	var specYAML string
 	var inputYAML string
+	var preset string
+	var lang string
 	var overwrite bool
+	var skipVersionCheck bool
 
 	cmd := &cobra.Command{
 		Use:   commandUse,
@@ -46,11 +58,15 @@ func Generate(globals func() error) *cobra.Command {
 				return err
 			}
 			return generate.Generate(&generate.Params{
-				Src:       src,
-				Dest:      dest,
-				SpecYAML:  specYAML,
-				InputYAML: inputYAML,
-				Overwrite: overwrite,
+				Src:              src,
+				Dest:             dest,
+				SpecYAML:         specYAML,
+				InputYAML:        inputYAML,
+				Preset:           preset,
+				Lang:             lang,
+				Overwrite:        overwrite,
+				CLIVersion:       version,
+				SkipVersionCheck: skipVersionCheck,
 			})
 		},
 	}
@@ -83,6 +99,20 @@ func Generate(globals func() error) *cobra.Command {
 		flagDescriptionInput,
 	)
 
+	cmd.Flags().StringVar(
+		&preset,
+		flagPreset,
+		"",
+		flagDescriptionPreset,
+	)
+
+	cmd.Flags().StringVar(
+		&lang,
+		flagLang,
+		"",
+		flagDescriptionLang,
+	)
+
 	cmd.Flags().BoolVar(
 		&overwrite,
 		flagOverwrite,
@@ -90,5 +120,12 @@ func Generate(globals func() error) *cobra.Command {
 		flagDescriptionOverwrite,
 	)
 
+	cmd.Flags().BoolVar(
+		&skipVersionCheck,
+		flagSkipVersionCheck,
+		false,
+		flagDescriptionSkipVersionCheck,
+	)
+
 	return cmd
 }
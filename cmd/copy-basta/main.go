@@ -70,7 +70,7 @@ func execute() error {
 	globals.register(cmd)
 
 	cmd.AddCommand(commands.Init(globals.process))
-	cmd.AddCommand(commands.Generate(globals.process))
+	cmd.AddCommand(commands.Generate(globals.process, version))
 
 	return cmd.Execute()
 }
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"os"
+	"strings"
+)
+
+// DefaultLanguage is used when no language is set, and when a text has no translation in the chosen one
+const DefaultLanguage = "en"
+
+// Language returns the language of the prompts: the flag value when set,
+// otherwise the one of the LC_ALL or LANG locale (pt_BR.UTF-8 is pt_BR)
+func Language(flag string) string {
+	for _, lang := range []string{flag, os.Getenv("LC_ALL"), os.Getenv("LANG")} {
+		if lang = normalizeLanguage(lang); lang != "" {
+			return lang
+		}
+	}
+	return DefaultLanguage
+}
+
+func normalizeLanguage(locale string) string {
+	if i := strings.IndexAny(locale, ".@"); i >= 0 {
+		locale = locale[:i]
+	}
+	if locale == "C" || locale == "POSIX" {
+		return ""
+	}
+	return strings.Replace(locale, "-", "_", -1)
+}
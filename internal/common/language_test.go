@@ -0,0 +1,41 @@
+package common_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_Language(t *testing.T) {
+	tests := []struct {
+		name     string
+		flag     string
+		lcAll    string
+		lang     string
+		expected string
+	}{
+		{name: "default", expected: "en"},
+		{name: "flag", flag: "it", lang: "pt_BR.UTF-8", expected: "it"},
+		{name: "lang", lang: "pt_BR.UTF-8", expected: "pt_BR"},
+		{name: "lc all over lang", lcAll: "fr_FR", lang: "pt_BR.UTF-8", expected: "fr_FR"},
+		{name: "posix locale", lang: "C.UTF-8", expected: "en"},
+		{name: "dashed flag", flag: "pt-BR", expected: "pt_BR"},
+	}
+
+	lcAll, lang := os.Getenv("LC_ALL"), os.Getenv("LANG")
+	defer func() {
+		os.Setenv("LC_ALL", lcAll)
+		os.Setenv("LANG", lang)
+	}()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Setenv("LC_ALL", tt.lcAll)
+			os.Setenv("LANG", tt.lang)
+			require.Equal(t, tt.expected, common.Language(tt.flag))
+		})
+	}
+}
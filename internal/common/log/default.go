@@ -77,8 +77,12 @@ type Logger struct {
 	trace         bool
 	levelColors   map[Level]common.Color
 	levelBGColors map[Level]common.BGColor
+	secrets       []string
 }
 
+// Redacted replaces the secrets in the logs, and masks them when displayed
+const Redacted = "******"
+
 func NewLogger() Logger {
 	l := Logger{
 		level:  warnLevel,
@@ -114,6 +118,14 @@ func (l *Logger) DisableTrace() {
 	l.trace = true
 }
 
+// Redact hides the secret from every message and data value logged from now on
+func (l *Logger) Redact(secret string) {
+	if secret == "" {
+		return
+	}
+	l.secrets = append(l.secrets, secret)
+}
+
 func (l *Logger) SetColor(level Level, color common.Color) {
 	l.levelColors[level] = color
 }
@@ -173,7 +185,7 @@ func (l *Logger) log(level Level, data Data, userMsg string) {
 	levelMsg := common.ColoredFormat(color, common.TextFormatBold, bgColor, fmt.Sprintf("[%s]", level.String()))
 
 	lineBuilder := strings.Builder{}
-	lineBuilder.WriteString(fmt.Sprintf("%s	%s", levelMsg, userMsg))
+	lineBuilder.WriteString(fmt.Sprintf("%s	%s", levelMsg, l.redact(userMsg)))
 	if l.trace || level == debugLevel {
 		if _, fn, fl, ok := runtime.Caller(2); ok {
 			lineBuilder.WriteString(fmt.Sprintf("\n        @ %s:%d", fn, fl))
@@ -183,7 +195,7 @@ func (l *Logger) log(level Level, data Data, userMsg string) {
 
 	for k, v := range data {
 		fmtK := common.ColoredFormat(color, common.TextFormatNormal, bgColor, k)
-		lineBuilder.WriteString(fmt.Sprintf("        %s: %v\n", fmtK, v))
+		lineBuilder.WriteString(fmt.Sprintf("        %s: %s\n", fmtK, l.redact(fmt.Sprintf("%v", v))))
 	}
 
 	if _, err := fmt.Fprint(l.writer, lineBuilder.String()); err != nil {
@@ -191,6 +203,13 @@ func (l *Logger) log(level Level, data Data, userMsg string) {
 	}
 }
 
+func (l *Logger) redact(s string) string {
+	for _, secret := range l.secrets {
+		s = strings.ReplaceAll(s, secret, Redacted)
+	}
+	return s
+}
+
 func (l *Logger) color(level Level) common.Color {
 	if color, ok := l.levelColors[level]; ok {
 		return color
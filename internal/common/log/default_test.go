@@ -79,3 +79,18 @@ func Test_Logger(t *testing.T) {
 		})
 	}
 }
+
+func Test_Logger_Redact(t *testing.T) {
+	w := &strings.Builder{}
+	logger := NewLogger()
+	logger.SetLevel(debugLevel)
+	logger.SetWriter(w)
+	logger.Redact("p4ssw0rd")
+	logger.Redact("")
+
+	logger.DebugWithData("value is p4ssw0rd", Data{"value": "p4ssw0rd", "nested": []string{"x", "p4ssw0rd"}, "other": "visible"})
+
+	require.NotContains(t, w.String(), "p4ssw0rd")
+	require.Contains(t, w.String(), "visible")
+	require.Contains(t, w.String(), Redacted)
+}
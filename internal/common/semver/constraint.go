@@ -0,0 +1,92 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var tolerantRegexp = regexp.MustCompile(`^v?(0|[1-9]\d*)(?:\.(0|[1-9]\d*))?(?:\.(0|[1-9]\d*))?$`)
+
+// ParseTolerant parses a semantic version, or a version missing its minor or patch numbers (`1.21` is `1.21.0`)
+func ParseTolerant(s string) (*Version, error) {
+	if v, err := Parse(s); err == nil {
+		return v, nil
+	}
+
+	m := tolerantRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("semver error: `%s` is not a version", s)
+	}
+	v := Version{}
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		if m[i+1] == "" {
+			continue
+		}
+		number, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("semver error: `%s` is not a version", s)
+		}
+		*n = number
+	}
+	return &v, nil
+}
+
+var operators = map[string]func(c int) bool{
+	"=":  func(c int) bool { return c == 0 },
+	"!=": func(c int) bool { return c != 0 },
+	">":  func(c int) bool { return c > 0 },
+	">=": func(c int) bool { return c >= 0 },
+	"<":  func(c int) bool { return c < 0 },
+	"<=": func(c int) bool { return c <= 0 },
+}
+
+var comparisonRegexp = regexp.MustCompile(`^(=|!=|>=|>|<=|<)?\s*(\S+)$`)
+
+// A Constraint is a list of comparisons a version must satisfy (`>= 1.21, < 2`)
+type Constraint struct {
+	raw         string
+	comparisons []comparison
+}
+
+type comparison struct {
+	operator string
+	version  Version
+}
+
+// ParseConstraint parses comma separated comparisons, with the =, !=, >, >=, < and <= operators.
+// a version without operator must be equal
+func ParseConstraint(s string) (*Constraint, error) {
+	c := Constraint{raw: s}
+	for _, part := range strings.Split(s, ",") {
+		m := comparisonRegexp.FindStringSubmatch(strings.TrimSpace(part))
+		if m == nil {
+			return nil, fmt.Errorf("semver error: `%s` is not a version constraint", s)
+		}
+		v, err := ParseTolerant(m[2])
+		if err != nil {
+			return nil, fmt.Errorf("semver error: `%s` is not a version constraint", s)
+		}
+		operator := m[1]
+		if operator == "" {
+			operator = "="
+		}
+		c.comparisons = append(c.comparisons, comparison{operator: operator, version: *v})
+	}
+	return &c, nil
+}
+
+// Check returns whether the version satisfies all the comparisons
+func (c *Constraint) Check(v Version) bool {
+	for _, cmp := range c.comparisons {
+		if !operators[cmp.operator](v.Compare(cmp.version)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Constraint) String() string {
+	return c.raw
+}
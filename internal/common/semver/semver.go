@@ -0,0 +1,110 @@
+package semver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// https://semver.org/#is-there-a-suggested-regular-expression-regex-to-check-a-semver-string
+var semverRegexp = regexp.MustCompile(
+	`^v?(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`,
+)
+
+// A Version is a semantic version (https://semver.org)
+type Version struct {
+	Major      int
+	Minor      int
+	Patch      int
+	PreRelease string
+	Build      string
+}
+
+// Parse parses a semantic version. the `v` prefix is optional
+func Parse(s string) (*Version, error) {
+	m := semverRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return nil, fmt.Errorf("semver error: `%s` is not a semantic version", s)
+	}
+
+	v := Version{PreRelease: m[4], Build: m[5]}
+	for i, n := range []*int{&v.Major, &v.Minor, &v.Patch} {
+		number, err := strconv.Atoi(m[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("semver error: `%s` is not a semantic version", s)
+		}
+		*n = number
+	}
+	return &v, nil
+}
+
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.PreRelease != "" {
+		s += "-" + v.PreRelease
+	}
+	if v.Build != "" {
+		s += "+" + v.Build
+	}
+	return s
+}
+
+// Compare returns -1, 0 or 1 when v is lower, equal or greater than other.
+// build metadata is ignored, as per the spec
+func (v Version) Compare(other Version) int {
+	for _, pair := range [][2]int{{v.Major, other.Major}, {v.Minor, other.Minor}, {v.Patch, other.Patch}} {
+		if c := compareInts(pair[0], pair[1]); c != 0 {
+			return c
+		}
+	}
+	return comparePreReleases(v.PreRelease, other.PreRelease)
+}
+
+func comparePreReleases(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	aIDs := strings.Split(a, ".")
+	bIDs := strings.Split(b, ".")
+	for i := 0; i < len(aIDs) && i < len(bIDs); i++ {
+		if c := compareIdentifiers(aIDs[i], bIDs[i]); c != 0 {
+			return c
+		}
+	}
+	return compareInts(len(aIDs), len(bIDs))
+}
+
+func compareIdentifiers(a, b string) int {
+	aN, aErr := strconv.Atoi(a)
+	bN, bErr := strconv.Atoi(b)
+	switch {
+	case aErr == nil && bErr == nil:
+		return compareInts(aN, bN)
+	case aErr == nil:
+		return -1
+	case bErr == nil:
+		return 1
+	default:
+		return strings.Compare(a, b)
+	}
+}
+
+func compareInts(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
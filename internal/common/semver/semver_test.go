@@ -0,0 +1,114 @@
+package semver_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common/semver"
+)
+
+func Test_Parse(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected semver.Version
+	}{
+		{in: "1.2.3", expected: semver.Version{Major: 1, Minor: 2, Patch: 3}},
+		{in: "v0.10.0", expected: semver.Version{Major: 0, Minor: 10, Patch: 0}},
+		{in: "1.0.0-rc.1+build.5", expected: semver.Version{Major: 1, PreRelease: "rc.1", Build: "build.5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := semver.Parse(tt.in)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, *v)
+		})
+	}
+}
+
+func Test_Parse_error(t *testing.T) {
+	for _, in := range []string{"", "1.2", "01.2.3", "1.2.3-", "snapshot-user-4334710"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := semver.Parse(in)
+			require.NotNil(t, err)
+		})
+	}
+}
+
+func Test_Version_Compare(t *testing.T) {
+	ordered := []string{"1.0.0-alpha", "1.0.0-alpha.1", "1.0.0-alpha.beta", "1.0.0-beta", "1.0.0-beta.2", "1.0.0-beta.11", "1.0.0-rc.1", "1.0.0", "1.0.1", "1.1.0", "2.0.0"}
+
+	for i := range ordered {
+		for j := range ordered {
+			a, err := semver.Parse(ordered[i])
+			require.Nil(t, err)
+			b, err := semver.Parse(ordered[j])
+			require.Nil(t, err)
+
+			expected := 0
+			if i < j {
+				expected = -1
+			} else if i > j {
+				expected = 1
+			}
+			require.Equal(t, expected, a.Compare(*b), "%s vs %s", ordered[i], ordered[j])
+		}
+	}
+}
+
+func Test_ParseTolerant(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected semver.Version
+	}{
+		{in: "1.21.3", expected: semver.Version{Major: 1, Minor: 21, Patch: 3}},
+		{in: "1.21", expected: semver.Version{Major: 1, Minor: 21}},
+		{in: "v3", expected: semver.Version{Major: 3}},
+		{in: "1.0.0-rc.1", expected: semver.Version{Major: 1, PreRelease: "rc.1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			v, err := semver.ParseTolerant(tt.in)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, *v)
+		})
+	}
+}
+
+func Test_Constraint_Check(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    string
+		expected   bool
+	}{
+		{constraint: ">= 1.21", version: "1.21.0", expected: true},
+		{constraint: ">= 1.21", version: "1.20.14", expected: false},
+		{constraint: ">=1.21, <2", version: "1.22.1", expected: true},
+		{constraint: ">=1.21, <2", version: "2.0.0", expected: false},
+		{constraint: "3.12.4", version: "3.12.4", expected: true},
+		{constraint: "!= 3.12.4", version: "3.12.4", expected: false},
+		{constraint: "> 1.0.0", version: "1.0.0-rc.1", expected: false},
+		{constraint: "<= 20", version: "20.10.7", expected: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.constraint+" "+tt.version, func(t *testing.T) {
+			c, err := semver.ParseConstraint(tt.constraint)
+			require.Nil(t, err)
+			v, err := semver.ParseTolerant(tt.version)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, c.Check(*v))
+		})
+	}
+}
+
+func Test_ParseConstraint_error(t *testing.T) {
+	for _, in := range []string{"", ">= ", "~> 1.2", ">= 1.2,", "1.x"} {
+		t.Run(in, func(t *testing.T) {
+			_, err := semver.ParseConstraint(in)
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package common
+
+import (
+	"strings"
+	"text/template"
+	"unicode"
+)
+
+// TemplateFuncs are the functions available to every template copy-basta executes
+func TemplateFuncs() template.FuncMap {
+	return template.FuncMap{
+		"stringsToUpper": strings.ToUpper,
+		"stringsToLower": strings.ToLower,
+		"stringsTitle":   strings.Title,
+		"snake":          SnakeCase,
+		"kebab":          KebabCase,
+		"camel":          CamelCase,
+	}
+}
+
+// NewTemplate returns an empty template that fails on missing keys
+// and has access to the TemplateFuncs
+func NewTemplate(name string) *template.Template {
+	return template.New(name).
+		Option("missingkey=error").
+		Funcs(TemplateFuncs())
+}
+
+// SnakeCase converts `serviceName`, `ServiceName` or `service-name` into `service_name`
+func SnakeCase(s string) string {
+	return strings.Join(words(s), "_")
+}
+
+// KebabCase converts `serviceName`, `ServiceName` or `service_name` into `service-name`
+func KebabCase(s string) string {
+	return strings.Join(words(s), "-")
+}
+
+// CamelCase converts `ServiceName`, `service_name` or `service-name` into `serviceName`
+func CamelCase(s string) string {
+	ws := words(s)
+	for i := 1; i < len(ws); i++ {
+		ws[i] = strings.Title(ws[i])
+	}
+	return strings.Join(ws, "")
+}
+
+// words splits s into lower case words on separators and case changes.
+// acronyms are kept together: `HTTPServer` is split into `http` and `server`
+func words(s string) []string {
+	var ws []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			ws = append(ws, strings.ToLower(string(current)))
+			current = nil
+		}
+	}
+
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0:
+			prev := runes[i-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				flush()
+			}
+		}
+		current = append(current, r)
+	}
+	flush()
+
+	return ws
+}
@@ -0,0 +1,34 @@
+package common_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_Cases(t *testing.T) {
+	tests := []struct {
+		in    string
+		snake string
+		kebab string
+		camel string
+	}{
+		{in: "serviceName", snake: "service_name", kebab: "service-name", camel: "serviceName"},
+		{in: "ServiceName", snake: "service_name", kebab: "service-name", camel: "serviceName"},
+		{in: "service-name", snake: "service_name", kebab: "service-name", camel: "serviceName"},
+		{in: "service_name", snake: "service_name", kebab: "service-name", camel: "serviceName"},
+		{in: "HTTPServer", snake: "http_server", kebab: "http-server", camel: "httpServer"},
+		{in: "v2Api", snake: "v2_api", kebab: "v2-api", camel: "v2Api"},
+		{in: "pizza", snake: "pizza", kebab: "pizza", camel: "pizza"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			require.Equal(t, tt.snake, common.SnakeCase(tt.in))
+			require.Equal(t, tt.kebab, common.KebabCase(tt.in))
+			require.Equal(t, tt.camel, common.CamelCase(tt.in))
+		})
+	}
+}
@@ -3,6 +3,11 @@ package crawl
 import (
 	"io"
 	"os"
+	"strings"
+
+	"copy-basta/internal/clients/github"
+	"copy-basta/internal/common"
+	"copy-basta/internal/common/log"
 )
 
 // A Crawler crawls the source project and returns all its files
@@ -15,3 +20,19 @@ type File struct {
 	Mode   os.FileMode
 	Reader io.Reader
 }
+
+// New returns the Crawler for the source location: a github repository or a local directory
+func New(src string) (Crawler, error) {
+	switch {
+	case strings.HasPrefix(src, common.GithubPrefix):
+		log.L.Debug("using github crawler")
+		ghc, err := github.NewClient(strings.TrimPrefix(src, common.GithubPrefix))
+		if err != nil {
+			return nil, err
+		}
+		return NewGithubCrawler(ghc), nil
+	default:
+		log.L.Debug("using disk crawler")
+		return NewLocalCrawler(src), nil
+	}
+}
@@ -3,8 +3,6 @@ package crawl
 import (
 	"os"
 	"path/filepath"
-
-	"copy-basta/internal/common"
 )
 
 type localCrawler struct {
@@ -27,11 +25,16 @@ func (c *localCrawler) Crawl() ([]File, error) {
 			return nil
 		}
 
+		relPath, err := filepath.Rel(c.root, fPath)
+		if err != nil {
+			return err
+		}
+
 		r, err := os.Open(fPath)
 		if err != nil {
 			return err
 		}
-		files = append(files, File{Path: common.TrimRootDir(fPath), Mode: info.Mode(), Reader: r})
+		files = append(files, File{Path: filepath.ToSlash(relPath), Mode: info.Mode(), Reader: r})
 
 		return nil
 	})
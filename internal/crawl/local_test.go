@@ -4,6 +4,7 @@ import (
 	"copy-basta/internal/crawl"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -66,3 +67,40 @@ func Test_LocalCrawler_Load(t *testing.T) {
 		require.Equal(t, expectedR, actualR)
 	}
 }
+
+func Test_LocalCrawler_nestedRoot(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "crawl")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	root := filepath.Join(tmp, "templates", "base")
+	require.Nil(t, os.MkdirAll(filepath.Join(root, "cmd"), os.ModePerm))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0600))
+	require.Nil(t, ioutil.WriteFile(filepath.Join(root, "cmd", "root.go"), []byte("package cmd\n"), 0600))
+
+	tests := []struct {
+		name string
+		root string
+	}{
+		{
+			name: "absolute root",
+			root: root,
+		},
+		{
+			name: "root with parent directories",
+			root: filepath.Join(root, "cmd", "..", "..", "base"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := crawl.NewLocalCrawler(tt.root).Crawl()
+			require.Nil(t, err)
+
+			var paths []string
+			for _, f := range files {
+				paths = append(paths, f.Path)
+			}
+			require.Equal(t, []string{"cmd/root.go", "main.go"}, paths)
+		})
+	}
+}
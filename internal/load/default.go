@@ -19,6 +19,32 @@ type File struct {
 	Mode     os.FileMode
 	Template bool
 	Content  []byte
+	// To is the template of the output path, it replaces the file path when set
+	To string
+	// Options are the options the file templates are rendered with
+	Options TemplateOptions
+	// Partial is the name of the partial template the file defines, if it is one.
+	// partials are not written, they are available to the other files templates
+	Partial string
+	// Scope is the namespace of the input variables the file is rendered with.
+	// it is set for the files of template components (`db`, or `db.cache` when nested)
+	Scope string
+}
+
+type TemplateOptions struct {
+	// Delimiters are the left and right action delimiters, the default ones are used when empty
+	Delimiters []string
+	// MissingKey is the text/template `missingkey` option, `error` when empty
+	MissingKey string
+}
+
+// A Rule holds the per-file settings
+type Rule struct {
+	To string
+	// Mode overrides the crawled file mode when set
+	Mode    os.FileMode
+	Options TemplateOptions
+	Partial string
 }
 
 type ignorer interface {
@@ -29,22 +55,31 @@ type passer interface {
 	Pass(string) bool
 }
 
+type ruler interface {
+	Rule(string) Rule
+}
+
 type loader struct {
 	ignorer ignorer
 	passer  passer
+	ruler   ruler
 }
 
-func New(ignorer ignorer, passer passer) (Loader, error) {
+func New(ignorer ignorer, passer passer, ruler ruler) (Loader, error) {
 	if ignorer == nil {
 		return nil, fmt.Errorf("ignorer can't be nil")
 	}
 	if passer == nil {
 		return nil, fmt.Errorf("passer can't be nil")
 	}
+	if ruler == nil {
+		return nil, fmt.Errorf("ruler can't be nil")
+	}
 
 	return &loader{
 		ignorer: ignorer,
 		passer:  passer,
+		ruler:   ruler,
 	}, nil
 }
 
@@ -54,7 +89,7 @@ func (l *loader) Load(crawledFiles []crawl.File) ([]File, error) {
 		return nil, err
 	}
 
-	files, err := processFiles(l.ignorer, l.passer, crawledFiles)
+	files, err := processFiles(l.ignorer, l.passer, l.ruler, crawledFiles)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +110,11 @@ func validateFiles(files []crawl.File) error {
 	return nil
 }
 
-func processFiles(ignorer ignorer, passer passer, crawledFiles []crawl.File) ([]File, error) {
+func processFiles(ignorer ignorer, passer passer, ruler ruler, crawledFiles []crawl.File) ([]File, error) {
 	var files []File
+	// renamed maps the output path template of renamed files to their path. two files with the
+	// same template would be written to the same path
+	renamed := map[string]string{}
 	for _, crawledFile := range crawledFiles {
 		if ignorer.Ignore(crawledFile.Path) {
 			continue
@@ -86,11 +124,26 @@ func processFiles(ignorer ignorer, passer passer, crawledFiles []crawl.File) ([]
 			return nil, err
 		}
 
+		rule := ruler.Rule(crawledFile.Path)
+		mode := crawledFile.Mode
+		if rule.Mode != 0 {
+			mode = rule.Mode
+		}
+		if rule.To != "" {
+			if other, found := renamed[rule.To]; found {
+				return nil, fmt.Errorf("`%s` and `%s` are both written to `%s`, a file rule `to` must match a single file", other, crawledFile.Path, rule.To)
+			}
+			renamed[rule.To] = crawledFile.Path
+		}
+
 		files = append(files, File{
 			Path:     crawledFile.Path,
-			Mode:     crawledFile.Mode,
+			Mode:     mode,
 			Template: !passer.Pass(crawledFile.Path),
 			Content:  content,
+			To:       rule.To,
+			Options:  rule.Options,
+			Partial:  rule.Partial,
 		})
 	}
 	return files, nil
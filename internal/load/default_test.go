@@ -40,6 +40,15 @@ func (i *testPasser) Pass(s string) bool {
 	return strings.Contains(s, "pass")
 }
 
+type testRuler struct{}
+
+func (r *testRuler) Rule(s string) Rule {
+	if strings.Contains(s, "rule") {
+		return Rule{To: "renamed/" + s, Mode: 0755, Options: TemplateOptions{MissingKey: "zero"}}
+	}
+	return Rule{}
+}
+
 func Test_processFiles(t *testing.T) {
 	loadedFiles := []crawl.File{
 		{
@@ -57,6 +66,11 @@ func Test_processFiles(t *testing.T) {
 			Mode:   0123,
 			Reader: strings.NewReader("template.cpp"),
 		},
+		{
+			Path:   "rule.sh",
+			Mode:   0644,
+			Reader: strings.NewReader("rule.sh"),
+		},
 	}
 
 	expectedFiles := []File{
@@ -72,9 +86,34 @@ func Test_processFiles(t *testing.T) {
 			Content:  []byte("template.cpp"),
 			Template: true,
 		},
+		{
+			Path:     "rule.sh",
+			Mode:     0755,
+			Content:  []byte("rule.sh"),
+			Template: true,
+			To:       "renamed/rule.sh",
+			Options:  TemplateOptions{MissingKey: "zero"},
+		},
 	}
 
-	files, err := processFiles(&testIgnorer{}, &testPasser{}, loadedFiles)
+	files, err := processFiles(&testIgnorer{}, &testPasser{}, &testRuler{}, loadedFiles)
 	require.Nil(t, err)
 	require.Equal(t, expectedFiles, files)
 }
+
+type singleToRuler struct{}
+
+func (r *singleToRuler) Rule(s string) Rule {
+	return Rule{To: "config.yml"}
+}
+
+func Test_processFiles_sameTo(t *testing.T) {
+	loadedFiles := []crawl.File{
+		{Path: "dev.yml", Mode: 0644, Reader: strings.NewReader("dev")},
+		{Path: "prod.yml", Mode: 0644, Reader: strings.NewReader("prod")},
+	}
+
+	_, err := processFiles(&testIgnorer{}, &testPasser{}, &singleToRuler{}, loadedFiles)
+	require.NotNil(t, err)
+	require.Equal(t, "`dev.yml` and `prod.yml` are both written to `config.yml`, a file rule `to` must match a single file", err.Error())
+}
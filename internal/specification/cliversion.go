@@ -0,0 +1,74 @@
+package specification
+
+import (
+	"fmt"
+
+	"copy-basta/internal/common/semver"
+)
+
+// cliVersion is the version of the running cli. the specifications requiring a newer one are rejected
+// as soon as their header is decoded, before their other fields. the check is skipped when unset
+var cliVersion string
+
+// SetCLIVersion sets the cli version the minimum version of the specifications is checked against
+func SetCLIVersion(version string) {
+	cliVersion = version
+}
+
+func newMinCLIVersion(raw string) (*semver.Version, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := semver.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("specification error [min-cli-version]: %s", err.Error())
+	}
+	return v, nil
+}
+
+// checkCLIVersion checks the cli version is not older than the minimum version required by the specification
+func checkCLIVersion(minVersion string) error {
+	min, err := newMinCLIVersion(minVersion)
+	if err != nil || min == nil || cliVersion == "" {
+		return err
+	}
+	v, err := semver.Parse(cliVersion)
+	if err != nil {
+		return fmt.Errorf(
+			"the template requires copy-basta %s or newer, but this build version (%s) can't be checked. "+
+				"use --skip-version-check to generate anyway",
+			min, cliVersion,
+		)
+	}
+	if v.Compare(*min) < 0 {
+		return fmt.Errorf(
+			"the template requires copy-basta %s or newer (this is %s). please upgrade copy-basta",
+			min, cliVersion,
+		)
+	}
+	return nil
+}
+
+// cliVersionMet tells whether the cli version is known to satisfy the minimum version
+func cliVersionMet(minVersion string) bool {
+	if cliVersion == "" {
+		return false
+	}
+	return checkCLIVersion(minVersion) == nil
+}
+
+// maxVersion returns the newest of two versions, empty versions and invalid ones are ignored
+func maxVersion(a string, b string) string {
+	va, errA := semver.Parse(a)
+	vb, errB := semver.Parse(b)
+	switch {
+	case errA != nil:
+		return b
+	case errB != nil:
+		return a
+	case va.Compare(*vb) < 0:
+		return b
+	default:
+		return a
+	}
+}
@@ -0,0 +1,83 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_decode_minCLIVersion(t *testing.T) {
+	tests := []struct {
+		name       string
+		yml        string
+		cliVersion string
+		err        string
+	}{
+		{name: "no minimum", yml: "version: 3\n", cliVersion: "snapshot"},
+		{name: "newer", yml: "version: 3\nmin-cli-version: 1.2.0\n", cliVersion: "v1.3.0"},
+		{name: "same", yml: "version: 3\nmin-cli-version: 1.2.0\n", cliVersion: "1.2.0"},
+		{name: "skipped", yml: "version: 3\nmin-cli-version: 1.2.0\n"},
+		{name: "older", yml: "version: 3\nmin-cli-version: 1.2.0\n", cliVersion: "1.1.9", err: "please upgrade copy-basta"},
+		{name: "snapshot", yml: "version: 3\nmin-cli-version: 1.2.0\n", cliVersion: "snapshot-user-4334710", err: "--skip-version-check"},
+		{
+			name:       "older with newer fields",
+			yml:        "version: 3\nmin-cli-version: 9.0.0\nvariables:\n  - name: x\n    type: future\n",
+			cliVersion: "1.1.9",
+			err:        "the template requires copy-basta 9.0.0 or newer (this is 1.1.9). please upgrade copy-basta",
+		},
+		{
+			name:       "unknown keys of newer cli versions",
+			yml:        "version: 3\nmin-cli-version: 9.0.0\nfuture: true\n",
+			cliVersion: "10.0.0",
+			err:        "field future not found",
+		},
+	}
+	defer SetCLIVersion("")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetCLIVersion(tt.cliVersion)
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			if tt.err == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+			require.NotContains(t, err.Error(), "(the template requires")
+		})
+	}
+}
+
+func Test_newFromReader_minCLIVersion_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "invalid version",
+			yml:  "version: 3\nmin-cli-version: latest\n",
+			err:  "specification error [min-cli-version]",
+		},
+		{
+			name: "unknown keys of newer cli versions, unchecked",
+			yml:  "version: 3\nmin-cli-version: 9.0.0\nfuture: true\n",
+			err:  "(the template requires copy-basta 9.0.0 or newer)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func Test_maxVersion(t *testing.T) {
+	require.Equal(t, "1.3.0", maxVersion("1.2.0", "1.3.0"))
+	require.Equal(t, "1.3.0", maxVersion("1.3.0", "1.2.0"))
+	require.Equal(t, "1.2.0", maxVersion("", "1.2.0"))
+	require.Equal(t, "1.2.0", maxVersion("1.2.0", ""))
+}
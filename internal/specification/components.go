@@ -0,0 +1,181 @@
+package specification
+
+import (
+	"fmt"
+	"go/token"
+	"path"
+	"strings"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/common/log"
+	"copy-basta/internal/crawl"
+)
+
+// A Component is a template mounted in a subpath of another template.
+// its variables are namespaced by the component name (`db.host`)
+// and may be bound to the variables of the parent template
+type Component struct {
+	Name  string
+	Dest  string
+	Spec  *Spec
+	Files []crawl.File
+	bind  map[string]*expression
+}
+
+// LoadComponents crawls and loads the components of the template at src
+func (s *Spec) LoadComponents(src string, overwrite bool) error {
+	return s.loadComponents(src, overwrite, "", map[string]struct{}{src: {}})
+}
+
+func (s *Spec) loadComponents(src string, overwrite bool, namespace string, visited map[string]struct{}) error {
+	declared := map[string]struct{}{}
+	for _, v := range s.Variables {
+		declared[v.root()] = struct{}{}
+	}
+
+	for _, cd := range s.componentsData {
+		if !token.IsIdentifier(cd.Name) {
+			return fmt.Errorf("component error [name]: `%s` is not a valid name", cd.Name)
+		}
+		if _, ok := declared[cd.Name]; ok {
+			return fmt.Errorf("component error [name]: `%s` is already used", cd.Name)
+		}
+		declared[cd.Name] = struct{}{}
+
+		c, err := newComponent(src, cd, overwrite, qualify(namespace, cd.Name), visited)
+		if err != nil {
+			return fmt.Errorf("component error [%s]: %s", cd.Name, err.Error())
+		}
+		if err := c.validateBindings(s.Variables); err != nil {
+			return fmt.Errorf("component error [%s]: %s", cd.Name, err.Error())
+		}
+		s.Components = append(s.Components, c)
+	}
+	return nil
+}
+
+func newComponent(src string, cd ComponentData, overwrite bool, namespace string, visited map[string]struct{}) (*Component, error) {
+	componentSrc, err := resolveSource(src, cd.Src)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := visited[componentSrc]; ok {
+		return nil, fmt.Errorf("cycle detected at %s", componentSrc)
+	}
+	log.L.DebugWithData("loading component", log.Data{"name": cd.Name, "src": componentSrc})
+
+	dest := cd.Name
+	if cd.Dest != nil {
+		dest = *cd.Dest
+	}
+	dest = path.Clean(dest)
+	if path.IsAbs(dest) || dest == ".." || strings.HasPrefix(dest, "../") {
+		return nil, fmt.Errorf("dest (%s) must be within the generated project", dest)
+	}
+
+	files, data, err := crawlTemplate(componentSrc)
+	if err != nil {
+		return nil, err
+	}
+	files, data, err = Extend(componentSrc, files, data)
+	if err != nil {
+		return nil, err
+	}
+	spec, err := New(data, overwrite)
+	if err != nil {
+		return nil, err
+	}
+	if err := spec.HonorIgnoreFiles(files); err != nil {
+		return nil, err
+	}
+	if err := spec.LoadData(files); err != nil {
+		return nil, err
+	}
+	for i := range spec.Variables {
+		spec.Variables[i].namespace = namespace
+	}
+
+	childVisited := map[string]struct{}{componentSrc: {}}
+	for k := range visited {
+		childVisited[k] = struct{}{}
+	}
+	if err := spec.loadComponents(componentSrc, overwrite, namespace, childVisited); err != nil {
+		return nil, err
+	}
+
+	bind := map[string]*expression{}
+	for name, raw := range cd.Bind {
+		e, err := newExpression(name, raw)
+		if err != nil {
+			return nil, fmt.Errorf("bind error [%s]: %s", name, err.Error())
+		}
+		bind[name] = e
+	}
+
+	return &Component{
+		Name:  cd.Name,
+		Dest:  dest,
+		Spec:  spec,
+		Files: files,
+		bind:  bind,
+	}, nil
+}
+
+// validateBindings checks that the bound variables exist in the component
+// and that the bindings only reference the parent variables
+func (c *Component) validateBindings(parent Variables) error {
+	parentNames := map[string]struct{}{}
+	for _, v := range parent {
+		parentNames[v.root()] = struct{}{}
+	}
+
+	for name, e := range c.bind {
+		v := c.Spec.Variables.get(name)
+		if v == nil {
+			return fmt.Errorf("bind error [%s]: the component has no such variable", name)
+		}
+		if v.isPath() && *v.dtype == pathDirectory {
+			ref, _ := e.reference()
+			if bound := parent.get(ref); bound == nil || !bound.isPath() || *bound.dtype != pathDirectory {
+				return fmt.Errorf("bind error [%s]: a directory variable can only be bound to a directory variable (`{{ .name }}`)", name)
+			}
+		}
+		for _, field := range e.fields() {
+			if _, ok := parentNames[field]; !ok {
+				return fmt.Errorf("bind error [%s]: references undeclared variable `%s`", name, field)
+			}
+		}
+	}
+	return nil
+}
+
+// bound renders the component bindings against the parent input. the file and directory variables
+// bound to a parent variable get its value as is
+func (c *Component) bound(parentInput common.InputVariables) (common.InputVariables, error) {
+	values := common.InputVariables{}
+	for name, e := range c.bind {
+		v := c.Spec.Variables.get(name)
+		if ref, ok := e.reference(); ok && v.isPath() {
+			value, _ := lookupValue(parentInput, ref)
+			values[name] = boundValue{value: value}
+			continue
+		}
+		value, err := v.renderValue(e, parentInput)
+		if err != nil {
+			return nil, fmt.Errorf("component error [%s]: bind error [%s]: %s", c.Name, name, err.Error())
+		}
+		if v.isPath() {
+			values[name] = boundValue{value: value}
+			continue
+		}
+		values[name] = value
+	}
+	return values, nil
+}
+
+func qualify(namespace string, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
@@ -0,0 +1,148 @@
+package specification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+const postgresComponentYAML = `---
+version: 2
+ignore:
+  - basta.yaml
+variables:
+  - name: host
+    type: string
+  - name: port
+    type: integer
+    default: 5432
+`
+
+func newTestComponentsSpec(t *testing.T, root string, parentYAML string) *Spec {
+	writeTestTemplate(t, filepath.Join(root, "postgres"), map[string]string{
+		"basta.yaml":    postgresComponentYAML,
+		"db/config.yml": "host: {{ .host }}\nport: {{ .port }}\n",
+	})
+
+	src := filepath.Join(root, "service")
+	spec, err := newFromReader(strings.NewReader(parentYAML), false)
+	require.Nil(t, err)
+	require.Nil(t, spec.LoadComponents(src, false))
+	return spec
+}
+
+func Test_Spec_LoadComponents(t *testing.T) {
+	root, err := ioutil.TempDir("", "components")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	spec := newTestComponentsSpec(t, root, `---
+version: 2
+variables:
+  - name: name
+    type: string
+components:
+  - name: db
+    src: ../postgres
+    dest: storage
+    bind:
+      host: "{{ .name }}-db"
+`)
+
+	require.Equal(t, 1, len(spec.Components))
+	c := spec.Components[0]
+	require.Equal(t, "db", c.Name)
+	require.Equal(t, "storage", c.Dest)
+	require.Equal(t, 2, len(c.Files))
+	require.Equal(t, "db.host", c.Spec.Variables[0].qualifiedName())
+
+	expected := common.InputVariables{
+		"name": "users",
+		"db":   common.InputVariables{"host": "users-db", "port": 6543},
+	}
+
+	input, err := spec.fromInput(common.InputVariables{
+		"name": "users",
+		"db":   map[interface{}]interface{}{"port": 6543, "host": "ignored"},
+	})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = spec.fromReader(newLineReader(strings.NewReader("users\n6543\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
+
+func Test_Spec_LoadComponents_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+	}{
+		{
+			name: "unknown component variable",
+			yml: `---
+components:
+  - name: db
+    src: ../postgres
+    bind:
+      hostname: localhost
+`,
+		},
+		{
+			name: "undeclared parent variable",
+			yml: `---
+components:
+  - name: db
+    src: ../postgres
+    bind:
+      host: "{{ .name }}"
+`,
+		},
+		{
+			name: "name collision",
+			yml: `---
+variables:
+  - name: db
+components:
+  - name: db
+    src: ../postgres
+`,
+		},
+		{
+			name: "invalid name",
+			yml: `---
+components:
+  - name: my-db
+    src: ../postgres
+`,
+		},
+		{
+			name: "dest outside project",
+			yml: `---
+components:
+  - name: db
+    src: ../postgres
+    dest: ../db
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "components")
+			require.Nil(t, err)
+			defer func() { _ = os.RemoveAll(root) }()
+			writeTestTemplate(t, filepath.Join(root, "postgres"), map[string]string{"basta.yaml": postgresComponentYAML})
+
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			require.NotNil(t, spec.LoadComponents(filepath.Join(root, "service"), false))
+		})
+	}
+}
@@ -1,19 +1,92 @@
 package specification
 
 type SpecData struct {
-	Ignore      []string       `yaml:"ignore"`
-	PassThrough []string       `yaml:"pass-through"`
-	Variables   []VariableData `yaml:"variables"`
-	OnOverwrite OnOverwrite    `yaml:"on-overwrite"`
+	Version       int                               `yaml:"version"`
+	MinCLIVersion string                            `yaml:"min-cli-version"`
+	Metadata      *Metadata                         `yaml:"metadata"`
+	Extends       string                            `yaml:"extends"`
+	Ignore        []string                          `yaml:"ignore"`
+	PassThrough   []string                          `yaml:"pass-through"`
+	Variables     []VariableData                    `yaml:"variables"`
+	OnOverwrite   OnOverwrite                       `yaml:"on-overwrite"`
+	Components    []ComponentData                   `yaml:"components"`
+	Files         []FileRuleData                    `yaml:"files"`
+	Delimiters    []string                          `yaml:"delimiters"`
+	Partials      string                            `yaml:"partials"`
+	Message       *Text                             `yaml:"message"`
+	Requires      []RequirementData                 `yaml:"requires"`
+	Presets       map[string]map[string]interface{} `yaml:"presets"`
+	Data          []DataFileData                    `yaml:"data"`
+	Groups        []GroupData                       `yaml:"groups"`
+	// HonorIgnoreFiles ignores the patterns of the template .gitignore and .bastaignore files, true when unset
+	HonorIgnoreFiles *bool `yaml:"honor-ignore-files"`
+	// LegacyPatterns keeps the patterns semantics of the specifications before version 3
+	LegacyPatterns bool `yaml:"legacy-patterns"`
+	// LegacyNames keeps the variable names of the specifications before version 3: any name, used as is as input key
+	LegacyNames bool `yaml:"-"`
+}
+
+// specDataV1 is the unversioned specification.
+// it accepted `passed-through` as an alias of `pass-through`
+type specDataV1 struct {
+	SpecData      `yaml:",inline"`
+	PassedThrough []string `yaml:"passed-through"`
 }
 
 type VariableData struct {
-	Name        string      `yaml:"name"`
-	DType       *string     `yaml:"type"`
-	DefaultVal  interface{} `yaml:"default"`
-	Description *string     `yaml:"description"`
+	Name        string        `yaml:"name"`
+	DType       *string       `yaml:"type"`
+	Format      *string       `yaml:"format"`
+	DefaultVal  interface{}   `yaml:"default"`
+	Description *Text         `yaml:"description"`
+	Help        *Text         `yaml:"help"`
+	When        *string       `yaml:"when"`
+	Value       *string       `yaml:"value"`
+	Secret      bool          `yaml:"secret"`
+	Required    *bool         `yaml:"required"`
+	Enum        []interface{} `yaml:"enum"`
+	EnumFrom    *string       `yaml:"enum-from"`
+	Group       *string       `yaml:"group"`
+	MaxSize     *string       `yaml:"max-size"`
+	Encoding    *string       `yaml:"encoding"`
 }
 
 type OnOverwrite struct {
 	Exclude []string `yaml:"exclude"`
 }
+
+type ComponentData struct {
+	Name string            `yaml:"name"`
+	Src  string            `yaml:"src"`
+	Dest *string           `yaml:"dest"`
+	Bind map[string]string `yaml:"bind"`
+}
+
+type FileRuleData struct {
+	Path       string   `yaml:"path"`
+	When       *string  `yaml:"when"`
+	To         *string  `yaml:"to"`
+	Mode       *string  `yaml:"mode"`
+	Delimiters []string `yaml:"delimiters"`
+	MissingKey *string  `yaml:"missingkey"`
+}
+
+type RequirementData struct {
+	Name    string  `yaml:"name"`
+	Version *string `yaml:"version"`
+	Command *string `yaml:"command"`
+	Regex   *string `yaml:"regex"`
+}
+
+type DataFileData struct {
+	Name    string `yaml:"name"`
+	Path    string `yaml:"path"`
+	Include bool   `yaml:"include"`
+}
+
+type GroupData struct {
+	Name        string `yaml:"name"`
+	Title       *Text  `yaml:"title"`
+	Description *Text  `yaml:"description"`
+	Ask         *Text  `yaml:"ask"`
+}
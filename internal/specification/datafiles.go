@@ -0,0 +1,148 @@
+package specification
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/crawl"
+)
+
+// dataNamespace is the input namespace the templates access the data files with (`{{ .data.licenses }}`)
+const dataNamespace = "data"
+
+func validateDataFiles(dataFiles []DataFileData, variables Variables) error {
+	names := map[string]struct{}{}
+	for _, df := range dataFiles {
+		if df.Name == "" {
+			return fmt.Errorf("data error [name]: is required")
+		}
+		if _, ok := names[df.Name]; ok {
+			return fmt.Errorf("data error [%s]: name is already used", df.Name)
+		}
+		names[df.Name] = struct{}{}
+		if df.Path == "" {
+			return fmt.Errorf("data error [%s]: path is required", df.Name)
+		}
+	}
+
+	for _, v := range variables {
+		if v.enumFrom == nil {
+			continue
+		}
+		if _, ok := names[strings.Split(*v.enumFrom, ".")[0]]; !ok {
+			return fmt.Errorf("variable error [enum-from]: %s references an undeclared data file", *v.enumFrom)
+		}
+	}
+	return nil
+}
+
+// pathsMatcher matches a set of paths
+type pathsMatcher map[string]struct{}
+
+func (pm pathsMatcher) Match(s string) bool {
+	_, ok := pm[s]
+	return ok
+}
+
+// LoadData decodes the yaml, or json, data files among the crawled files, and resolves the
+// enum choices they hold, before the presets values are checked. data files are ignored unless they are included.
+// the data files readers are replaced, so they can still be loaded
+func (s *Spec) LoadData(files []crawl.File) error {
+	if len(s.dataFiles) == 0 {
+		return nil
+	}
+
+	index := map[string]int{}
+	for i, f := range files {
+		index[f.Path] = i
+	}
+
+	data := common.InputVariables{}
+	ignored := pathsMatcher{}
+	for _, df := range s.dataFiles {
+		p := path.Clean(df.Path)
+		i, ok := index[p]
+		if !ok {
+			return fmt.Errorf("data error [%s]: file %s not found", df.Name, p)
+		}
+		content, err := ioutil.ReadAll(files[i].Reader)
+		if err != nil {
+			return fmt.Errorf("data error [%s]: %s", df.Name, err.Error())
+		}
+		files[i].Reader = bytes.NewReader(content)
+
+		var value interface{}
+		if err := yaml.Unmarshal(content, &value); err != nil {
+			return fmt.Errorf("data error [%s]: %s", df.Name, err.Error())
+		}
+		data[df.Name] = value
+		if !df.Include {
+			ignored[p] = struct{}{}
+		}
+	}
+	s.data = data
+	s.Ignorer = s.Ignorer.with(ignored)
+
+	for i := range s.Variables {
+		if err := s.Variables[i].resolveEnum(data); err != nil {
+			return err
+		}
+	}
+	return s.validatePresets()
+}
+
+// resolveEnum sets the enum choices from the data the variable `enum-from` references:
+// a list, or a map whose sorted keys are the choices
+func (v *Variable) resolveEnum(data common.InputVariables) error {
+	if v.enumFrom == nil {
+		return nil
+	}
+
+	keys := strings.Split(*v.enumFrom, ".")
+	value, ok := data[keys[0]]
+	for _, key := range keys[1:] {
+		m, isMap := value.(map[interface{}]interface{})
+		if !ok || !isMap {
+			ok = false
+			break
+		}
+		value, ok = m[key]
+	}
+	if !ok {
+		return fmt.Errorf("variable error [enum-from]: %s not found in the data files", *v.enumFrom)
+	}
+
+	switch choices := value.(type) {
+	case []interface{}:
+		v.enum = choices
+	case map[interface{}]interface{}:
+		var keys []string
+		for k := range choices {
+			keys = append(keys, fmt.Sprintf("%v", k))
+		}
+		sort.Strings(keys)
+		v.enum = nil
+		for _, k := range keys {
+			v.enum = append(v.enum, k)
+		}
+	default:
+		return fmt.Errorf("variable error [enum-from]: %s is not a list nor a map", *v.enumFrom)
+	}
+
+	if err := v.validateEnum(); err != nil {
+		return err
+	}
+	if v.defaultVal != nil {
+		if err := v.valueOk(v.defaultVal); err != nil {
+			return fmt.Errorf("variable error [default]: %s", err.Error())
+		}
+	}
+	return nil
+}
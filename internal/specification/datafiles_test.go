@@ -0,0 +1,141 @@
+package specification
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/crawl"
+)
+
+const dataYAML = `---
+version: 3
+data:
+  - name: licenses
+    path: data/licenses.yaml
+  - name: regions
+    path: data/regions.json
+    include: true
+variables:
+  - name: license
+    type: string
+    enum-from: licenses
+    default: MIT
+  - name: region
+    type: string
+    enum-from: regions.aws
+`
+
+func newTestDataFiles() []crawl.File {
+	return []crawl.File{
+		{Path: "data/licenses.yaml", Reader: strings.NewReader("MIT: Massachusetts Institute of Technology\nApache-2.0: Apache License 2.0\n")},
+		{Path: "data/regions.json", Reader: strings.NewReader(`{"aws": ["eu-west-1", "us-east-1"]}`)},
+		{Path: "main.go", Reader: strings.NewReader("package main")},
+	}
+}
+
+func Test_Spec_LoadData(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(dataYAML), false)
+	require.Nil(t, err)
+	files := newTestDataFiles()
+	require.Nil(t, spec.LoadData(files))
+
+	require.True(t, spec.Ignorer.Ignore("data/licenses.yaml"))
+	require.False(t, spec.Ignorer.Ignore("data/regions.json"))
+	require.False(t, spec.Ignorer.Ignore("main.go"))
+
+	content, err := ioutil.ReadAll(files[1].Reader)
+	require.Nil(t, err)
+	require.Equal(t, `{"aws": ["eu-west-1", "us-east-1"]}`, string(content))
+
+	require.Equal(t, []interface{}{"Apache-2.0", "MIT"}, spec.Variables.get("license").enum)
+	require.Equal(t, []interface{}{"eu-west-1", "us-east-1"}, spec.Variables.get("region").enum)
+
+	input, err := spec.fromInput(common.InputVariables{"region": "us-east-1"})
+	require.Nil(t, err)
+	templateInput := spec.TemplateInput(input)
+	require.Equal(t, "MIT", templateInput["license"])
+	licenses := templateInput["data"].(common.InputVariables)["licenses"].(map[interface{}]interface{})
+	require.Equal(t, "Apache License 2.0", licenses["Apache-2.0"])
+
+	_, err = spec.fromInput(common.InputVariables{"region": "mars-north-1"})
+	require.NotNil(t, err)
+	require.Equal(t, "value error: `mars-north-1` is not one of [eu-west-1, us-east-1]", err.Error())
+}
+
+func Test_Spec_LoadData_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "missing file",
+			yml:  "version: 3\ndata:\n  - name: x\n    path: data/x.yaml\n",
+			err:  "data error [x]: file data/x.yaml not found",
+		},
+		{
+			name: "enum not found",
+			yml:  "version: 3\ndata:\n  - name: regions\n    path: data/regions.json\nvariables:\n  - name: r\n    type: string\n    enum-from: regions.gcp\n",
+			err:  "variable error [enum-from]: regions.gcp not found in the data files",
+		},
+		{
+			name: "default not in enum",
+			yml:  "version: 3\ndata:\n  - name: licenses\n    path: data/licenses.yaml\nvariables:\n  - name: l\n    type: string\n    enum-from: licenses\n    default: GPL\n",
+			err:  "variable error [default]: value error: `GPL` is not one of [Apache-2.0, MIT]",
+		},
+		{
+			name: "preset not in enum",
+			yml:  "version: 3\ndata:\n  - name: licenses\n    path: data/licenses.yaml\nvariables:\n  - name: l\n    type: string\n    enum-from: licenses\npresets:\n  gpl:\n    l: GPL\n",
+			err:  "preset error [gpl]: variable `l`: value error: `GPL` is not one of [Apache-2.0, MIT]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			err = spec.LoadData(newTestDataFiles())
+			require.NotNil(t, err)
+			require.Equal(t, tt.err, err.Error())
+		})
+	}
+}
+
+func Test_newFromReader_data_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "undeclared data file",
+			yml:  "version: 3\nvariables:\n  - name: r\n    type: string\n    enum-from: regions\n",
+			err:  "variable error [enum-from]: regions references an undeclared data file",
+		},
+		{
+			name: "reserved name",
+			yml:  "version: 3\ndata:\n  - name: x\n    path: x.yaml\nvariables:\n  - name: data\n    type: string\n",
+			err:  "`data` is reserved",
+		},
+		{
+			name: "enum of another type",
+			yml:  "version: 3\nvariables:\n  - name: n\n    type: integer\n    enum: [1, two]\n",
+			err:  "variable error [enum]",
+		},
+		{
+			name: "enum and enum-from",
+			yml:  "version: 3\ndata:\n  - name: x\n    path: x.yaml\nvariables:\n  - name: n\n    type: string\n    enum: [a]\n    enum-from: x\n",
+			err:  "variable error [enum]: enum and enum-from are mutually exclusive",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+)
+
+// dependencies returns the field paths referenced by the variable expressions
+func (v *Variable) dependencies() []string {
+	var deps []string
+	for _, e := range []*expression{v.defaultTmpl, v.when, v.value} {
+		if e != nil {
+			deps = append(deps, e.paths()...)
+		}
+	}
+	return deps
+}
+
+// resolve returns the names of the variables the field path refers to
+func (vars Variables) resolve(path string) []string {
+	var names []string
+	for i := range vars {
+		if vars[i].refersTo(path) {
+			names = append(names, vars[i].name)
+		}
+	}
+	return names
+}
+
+// sortVariables orders the variables so that each one comes after the variables it depends on.
+// the declared order is kept whenever possible
+func sortVariables(vars Variables) (Variables, error) {
+	deps := map[string][]string{}
+	for _, v := range vars {
+		for _, path := range v.dependencies() {
+			names := vars.resolve(path)
+			if len(names) == 0 {
+				return nil, fmt.Errorf("variable error [%s]: references undeclared variable `%s`", v.name, path)
+			}
+			for _, name := range names {
+				if name == v.name {
+					return nil, fmt.Errorf("variable error [%s]: references itself", v.name)
+				}
+			}
+			deps[v.name] = append(deps[v.name], names...)
+		}
+	}
+
+	sorted := Variables{}
+	placed := map[string]struct{}{}
+	pending := vars
+	for len(pending) > 0 {
+		var next Variables
+		progress := false
+		for _, v := range pending {
+			if progress || !allPlaced(deps[v.name], placed) {
+				next = append(next, v)
+				continue
+			}
+			sorted = append(sorted, v)
+			placed[v.name] = struct{}{}
+			progress = true
+		}
+		if !progress {
+			var names []string
+			for _, v := range pending {
+				names = append(names, v.name)
+			}
+			return nil, fmt.Errorf("variables error: dependency cycle between [%s]", strings.Join(names, ", "))
+		}
+		pending = next
+	}
+
+	return sorted, nil
+}
+
+func allPlaced(deps []string, placed map[string]struct{}) bool {
+	for _, dep := range deps {
+		if _, ok := placed[dep]; !ok {
+			return false
+		}
+	}
+	return true
+}
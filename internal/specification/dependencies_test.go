@@ -0,0 +1,87 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_expression_fields(t *testing.T) {
+	tests := []struct {
+		name     string
+		raw      string
+		expected []string
+	}{
+		{name: "none", raw: "static", expected: nil},
+		{name: "field", raw: "{{ .a }}", expected: []string{"a"}},
+		{name: "nested field", raw: "{{ .a.b }}", expected: []string{"a"}},
+		{name: "pipeline", raw: "{{ .a | snake }}-{{ .b }}-{{ .a }}", expected: []string{"a", "b"}},
+		{name: "if", raw: "{{ if .a }}{{ .b }}{{ else }}{{ .c }}{{ end }}", expected: []string{"a", "b", "c"}},
+		{name: "range", raw: "{{ range .a }}{{ .notInput }}{{ $.b }}{{ end }}", expected: []string{"a", "b"}},
+		{name: "function args", raw: `{{ eq .a "x" }}`, expected: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, err := newExpression(tt.name, tt.raw)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, e.fields())
+		})
+	}
+}
+
+func Test_sortVariables(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: serviceSnake
+    type: string
+    value: "{{ .serviceName | snake }}"
+  - name: org
+    type: string
+  - name: serviceName
+    type: string
+  - name: serviceKebab
+    type: string
+    value: "{{ .serviceName | kebab }}"
+`)
+	var names []string
+	for _, v := range vars {
+		names = append(names, v.name)
+	}
+	require.Equal(t, []string{"org", "serviceName", "serviceSnake", "serviceKebab"}, names)
+}
+
+func Test_sortVariables_error(t *testing.T) {
+	tests := []struct {
+		name string
+		vars []VariableData
+	}{
+		{
+			name: "undeclared",
+			vars: []VariableData{
+				{Name: "a", Value: func() *string { s := "{{ .b }}"; return &s }()},
+			},
+		},
+		{
+			name: "self",
+			vars: []VariableData{
+				{Name: "a", Value: func() *string { s := "{{ .a }}"; return &s }()},
+			},
+		},
+		{
+			name: "cycle",
+			vars: []VariableData{
+				{Name: "a", Value: func() *string { s := "{{ .b }}"; return &s }()},
+				{Name: "b", Value: func() *string { s := "{{ .c }}"; return &s }()},
+				{Name: "c", Value: func() *string { s := "{{ .a }}"; return &s }()},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newVariables(tt.vars, false)
+			require.NotNil(t, err)
+		})
+	}
+}
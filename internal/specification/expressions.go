@@ -0,0 +1,146 @@
+package specification
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"copy-basta/internal/common"
+)
+
+// An expression is a spec value holding a go template
+// that is executed against the input variables
+type expression struct {
+	raw string
+	t   *template.Template
+}
+
+func newExpression(name string, raw string) (*expression, error) {
+	t, err := common.NewTemplate(name).Parse(raw)
+	if err != nil {
+		return nil, err
+	}
+	return &expression{raw: raw, t: t}, nil
+}
+
+func (e *expression) render(input common.InputVariables) (string, error) {
+	w := strings.Builder{}
+	if err := e.t.Execute(&w, input); err != nil {
+		return "", err
+	}
+	return w.String(), nil
+}
+
+func (e *expression) isTrue(input common.InputVariables) (bool, error) {
+	s, err := e.render(input)
+	if err != nil {
+		return false, err
+	}
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		return false, fmt.Errorf("expression `%s` rendered `%s`, expected a boolean", e.raw, s)
+	}
+	return b, nil
+}
+
+// fields returns the top level input variables referenced by the expression
+func (e *expression) fields() []string {
+	return e.collect(func(idents []string) string { return idents[0] })
+}
+
+// paths returns the dotted field paths referenced by the expression, as in `db.host`
+func (e *expression) paths() []string {
+	return e.collect(func(idents []string) string { return strings.Join(idents, ".") })
+}
+
+// reference returns the dotted path of the field the expression only consists of, as in `{{ .db.host }}`
+func (e *expression) reference() (string, bool) {
+	if e.t.Tree == nil || len(e.t.Tree.Root.Nodes) != 1 {
+		return "", false
+	}
+	action, ok := e.t.Tree.Root.Nodes[0].(*parse.ActionNode)
+	if !ok || len(action.Pipe.Decl) > 0 || len(action.Pipe.Cmds) != 1 || len(action.Pipe.Cmds[0].Args) != 1 {
+		return "", false
+	}
+	field, ok := action.Pipe.Cmds[0].Args[0].(*parse.FieldNode)
+	if !ok {
+		return "", false
+	}
+	return strings.Join(field.Ident, "."), true
+}
+
+func (e *expression) collect(key func(idents []string) string) []string {
+	found := map[string]struct{}{}
+	var fields []string
+	add := func(idents []string) {
+		field := key(idents)
+		if _, ok := found[field]; !ok {
+			found[field] = struct{}{}
+			fields = append(fields, field)
+		}
+	}
+
+	for _, t := range e.t.Templates() {
+		if t.Tree != nil {
+			walkFields(t.Tree.Root, true, add)
+		}
+	}
+	return fields
+}
+
+// walkFields calls add with the identifiers of each field of the input variables found in node.
+// inside `range` and `with` blocks dot is rebound, so only `$` fields refer to the input
+func walkFields(node parse.Node, dotIsRoot bool, add func([]string)) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			walkFields(child, dotIsRoot, add)
+		}
+	case *parse.ActionNode:
+		walkFields(n.Pipe, dotIsRoot, add)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			walkFields(cmd, dotIsRoot, add)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			walkFields(arg, dotIsRoot, add)
+		}
+	case *parse.ChainNode:
+		walkFields(n.Node, dotIsRoot, add)
+	case *parse.FieldNode:
+		if dotIsRoot && len(n.Ident) > 0 {
+			add(n.Ident)
+		}
+	case *parse.VariableNode:
+		if len(n.Ident) > 1 && n.Ident[0] == "$" {
+			add(n.Ident[1:])
+		}
+	case *parse.IfNode:
+		walkBranch(&n.BranchNode, dotIsRoot, dotIsRoot, add)
+	case *parse.RangeNode:
+		walkBranch(&n.BranchNode, dotIsRoot, false, add)
+	case *parse.WithNode:
+		walkBranch(&n.BranchNode, dotIsRoot, false, add)
+	case *parse.TemplateNode:
+		walkFields(n.Pipe, dotIsRoot, add)
+	}
+}
+
+func walkBranch(n *parse.BranchNode, dotIsRoot bool, bodyDotIsRoot bool, add func([]string)) {
+	walkFields(n.Pipe, dotIsRoot, add)
+	walkFields(n.List, bodyDotIsRoot, add)
+	walkFields(n.ElseList, dotIsRoot, add)
+}
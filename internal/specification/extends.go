@@ -0,0 +1,323 @@
+package specification
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/common/log"
+	"copy-basta/internal/crawl"
+)
+
+// Extend resolves the template the specification extends, if any.
+// the parent files are the base layer and the child files override them,
+// the parent specification is merged with the child one
+func Extend(src string, files []crawl.File, data *SpecData) ([]crawl.File, *SpecData, error) {
+	return extend(src, files, data, map[string]struct{}{})
+}
+
+func extend(src string, files []crawl.File, data *SpecData, visited map[string]struct{}) ([]crawl.File, *SpecData, error) {
+	if data.Extends == "" {
+		return files, data, nil
+	}
+	visited[src] = struct{}{}
+
+	parentSrc, err := resolveSource(src, data.Extends)
+	if err != nil {
+		return nil, nil, err
+	}
+	if _, ok := visited[parentSrc]; ok {
+		return nil, nil, fmt.Errorf("specification error [extends]: cycle detected at %s", parentSrc)
+	}
+	log.L.DebugWithData("extending template", log.Data{"src": src, "parent": parentSrc})
+
+	parentFiles, parentData, err := crawlTemplate(parentSrc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("specification error [extends]: %s", err.Error())
+	}
+
+	parentFiles, parentData, err = extend(parentSrc, parentFiles, parentData, visited)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	parentData.Components, err = rebaseComponents(parentSrc, parentData.Components)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the patterns are merged, they can't be read with two semantics
+	if parentData.LegacyPatterns != data.LegacyPatterns {
+		return nil, nil, fmt.Errorf("specification error [extends]: %s uses the %s patterns semantics, the extending template must set legacy-patterns: %t", parentSrc, patternsSemantics(parentData.LegacyPatterns), parentData.LegacyPatterns)
+	}
+
+	// the delimiters apply to the files of both layers
+	if data.Delimiters != nil && !sameStrings(parentData.Delimiters, data.Delimiters) {
+		return nil, nil, fmt.Errorf("specification error [delimiters]: can't differ from the ones of %s, the extended template", parentSrc)
+	}
+
+	// the parent partials are still used by the parent files
+	if parentData.Partials != "" && data.Partials != "" && path.Clean(parentData.Partials) != path.Clean(data.Partials) {
+		return nil, nil, fmt.Errorf("specification error [partials]: can't differ from the ones of %s, the extended template (%s)", parentSrc, parentData.Partials)
+	}
+
+	return mergeFiles(parentFiles, files), mergeSpecData(parentData, data), nil
+}
+
+func sameStrings(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func patternsSemantics(legacy bool) string {
+	if legacy {
+		return "legacy"
+	}
+	return "gitignore"
+}
+
+// crawlTemplate crawls the template at src and loads its specification
+func crawlTemplate(src string) ([]crawl.File, *SpecData, error) {
+	crawler, err := crawl.New(src)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := crawler.Crawl()
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := Load(common.SpecFile, files)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, data, nil
+}
+
+// resolveSource resolves a template location referenced by the template at src.
+// local locations are relative to src
+func resolveSource(src string, location string) (string, error) {
+	switch {
+	case strings.HasPrefix(location, common.GithubPrefix):
+		return location, nil
+	case strings.HasPrefix(src, common.GithubPrefix):
+		return "", fmt.Errorf("specification error: remote templates can only reference remote templates (%s)", location)
+	case filepath.IsAbs(location):
+		return filepath.Clean(location), nil
+	default:
+		return filepath.Join(src, location), nil
+	}
+}
+
+// rebaseComponents resolves the components locations against the template declaring them,
+// so they still resolve once inherited by a template in another directory
+func rebaseComponents(src string, components []ComponentData) ([]ComponentData, error) {
+	var rebased []ComponentData
+	for _, cd := range components {
+		componentSrc, err := resolveSource(src, cd.Src)
+		if err != nil {
+			return nil, fmt.Errorf("component error [%s]: %s", cd.Name, err.Error())
+		}
+		if !strings.HasPrefix(componentSrc, common.GithubPrefix) {
+			componentSrc, err = filepath.Abs(componentSrc)
+			if err != nil {
+				return nil, fmt.Errorf("component error [%s]: %s", cd.Name, err.Error())
+			}
+		}
+		cd.Src = componentSrc
+		rebased = append(rebased, cd)
+	}
+	return rebased, nil
+}
+
+func mergeFiles(parent []crawl.File, child []crawl.File) []crawl.File {
+	childPaths := map[string]struct{}{}
+	for _, f := range child {
+		childPaths[f.Path] = struct{}{}
+	}
+
+	var files []crawl.File
+	for _, f := range parent {
+		if _, overridden := childPaths[f.Path]; !overridden {
+			files = append(files, f)
+		}
+	}
+	return append(files, child...)
+}
+
+func mergeSpecData(parent *SpecData, child *SpecData) *SpecData {
+	merged := *child
+	merged.Extends = ""
+	merged.MinCLIVersion = maxVersion(parent.MinCLIVersion, child.MinCLIVersion)
+	merged.Ignore = mergeStrings(parent.Ignore, child.Ignore)
+	merged.PassThrough = mergeStrings(parent.PassThrough, child.PassThrough)
+	merged.OnOverwrite.Exclude = mergeStrings(parent.OnOverwrite.Exclude, child.OnOverwrite.Exclude)
+	merged.Variables = mergeVariables(parent.Variables, child.Variables)
+	merged.Components = mergeComponents(parent.Components, child.Components)
+	merged.Requires = mergeRequirements(parent.Requires, child.Requires)
+	merged.Data = mergeDataFiles(parent.Data, child.Data)
+	merged.Groups = mergeGroups(parent.Groups, child.Groups)
+	merged.Presets = map[string]map[string]interface{}{}
+	for _, presets := range []map[string]map[string]interface{}{parent.Presets, child.Presets} {
+		for name, values := range presets {
+			merged.Presets[name] = values
+		}
+	}
+	merged.Files = append(append([]FileRuleData{}, parent.Files...), child.Files...)
+	if child.Delimiters == nil {
+		merged.Delimiters = parent.Delimiters
+	}
+	if child.Partials == "" {
+		merged.Partials = parent.Partials
+	}
+	if child.Metadata == nil {
+		merged.Metadata = parent.Metadata
+	}
+	if child.Message == nil {
+		merged.Message = parent.Message
+	}
+	if child.HonorIgnoreFiles == nil {
+		merged.HonorIgnoreFiles = parent.HonorIgnoreFiles
+	}
+	return &merged
+}
+
+// mergeComponents keeps the parent components. a child component replaces the parent one with the same name
+func mergeComponents(parent []ComponentData, child []ComponentData) []ComponentData {
+	all := append(append([]ComponentData{}, parent...), child...)
+	var merged []ComponentData
+	for _, i := range mergeNamed(len(all), func(i int) string { return all[i].Name }) {
+		merged = append(merged, all[i])
+	}
+	return merged
+}
+
+// mergeNamed merges the n named items of a parent and a child list, concatenated. it returns the indexes of the merged
+// items: an item replaces, in place, a previous one with the same name
+func mergeNamed(n int, name func(i int) string) []int {
+	var merged []int
+	index := map[string]int{}
+	for i := 0; i < n; i++ {
+		if j, ok := index[name(i)]; ok {
+			merged[j] = i
+			continue
+		}
+		index[name(i)] = len(merged)
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// mergeRequirements keeps the parent requirements. a child requirement replaces the parent one with the same name
+func mergeRequirements(parent []RequirementData, child []RequirementData) []RequirementData {
+	all := append(append([]RequirementData{}, parent...), child...)
+	var merged []RequirementData
+	for _, i := range mergeNamed(len(all), func(i int) string { return all[i].Name }) {
+		merged = append(merged, all[i])
+	}
+	return merged
+}
+
+// mergeDataFiles keeps the parent data files. a child data file replaces the parent one with the same name
+func mergeDataFiles(parent []DataFileData, child []DataFileData) []DataFileData {
+	all := append(append([]DataFileData{}, parent...), child...)
+	var merged []DataFileData
+	for _, i := range mergeNamed(len(all), func(i int) string { return all[i].Name }) {
+		merged = append(merged, all[i])
+	}
+	return merged
+}
+
+// mergeGroups keeps the parent groups. a child group replaces the parent one with the same name
+func mergeGroups(parent []GroupData, child []GroupData) []GroupData {
+	all := append(append([]GroupData{}, parent...), child...)
+	var merged []GroupData
+	for _, i := range mergeNamed(len(all), func(i int) string { return all[i].Name }) {
+		merged = append(merged, all[i])
+	}
+	return merged
+}
+
+func mergeStrings(parent []string, child []string) []string {
+	var merged []string
+	merged = append(merged, parent...)
+	return append(merged, child...)
+}
+
+// mergeVariables keeps the parent variables order. the fields set by a child variable
+// override the ones of the parent variable with the same name, the unset ones can't unset the parent fields
+// (a parent when or secret are kept)
+func mergeVariables(parent []VariableData, child []VariableData) []VariableData {
+	var merged []VariableData
+	index := map[string]int{}
+	for _, vd := range parent {
+		index[vd.Name] = len(merged)
+		merged = append(merged, vd)
+	}
+
+	for _, vd := range child {
+		i, ok := index[vd.Name]
+		if !ok {
+			index[vd.Name] = len(merged)
+			merged = append(merged, vd)
+			continue
+		}
+		merged[i] = mergeVariable(merged[i], vd)
+	}
+	return merged
+}
+
+func mergeVariable(parent VariableData, child VariableData) VariableData {
+	merged := parent
+	if child.DType != nil {
+		merged.DType = child.DType
+	}
+	if child.Format != nil {
+		merged.Format = child.Format
+	}
+	if child.DefaultVal != nil {
+		merged.DefaultVal = child.DefaultVal
+	}
+	if child.Description != nil {
+		merged.Description = child.Description
+	}
+	if child.Help != nil {
+		merged.Help = child.Help
+	}
+	if child.When != nil {
+		merged.When = child.When
+	}
+	if child.Value != nil {
+		merged.Value = child.Value
+	}
+	if child.Secret {
+		merged.Secret = true
+	}
+	if child.Required != nil {
+		merged.Required = child.Required
+	}
+	if child.Enum != nil {
+		merged.Enum = child.Enum
+	}
+	if child.EnumFrom != nil {
+		merged.EnumFrom = child.EnumFrom
+	}
+	if child.Group != nil {
+		merged.Group = child.Group
+	}
+	if child.MaxSize != nil {
+		merged.MaxSize = child.MaxSize
+	}
+	if child.Encoding != nil {
+		merged.Encoding = child.Encoding
+	}
+	return merged
+}
@@ -0,0 +1,281 @@
+package specification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/crawl"
+)
+
+func writeTestTemplate(t *testing.T, root string, files map[string]string) {
+	for p, content := range files {
+		fPath := filepath.Join(root, p)
+		require.Nil(t, os.MkdirAll(filepath.Dir(fPath), os.ModePerm))
+		require.Nil(t, ioutil.WriteFile(fPath, []byte(content), 0600))
+	}
+}
+
+func Test_Extend(t *testing.T) {
+	root, err := ioutil.TempDir("", "extends")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeTestTemplate(t, filepath.Join(root, "base"), map[string]string{
+		"basta.yaml": `---
+version: 2
+ignore:
+  - basta.yaml
+pass-through:
+  - static/
+variables:
+  - name: name
+    type: string
+    description: base description
+  - name: greet
+    type: string
+    default: hello
+`,
+		"main.sh":      "echo {{.greet}} {{.name}}",
+		"shared.txt":   "base",
+		"static/a.css": "a {}",
+	})
+	writeTestTemplate(t, filepath.Join(root, "child"), map[string]string{
+		"basta.yaml": `---
+version: 2
+extends: ../base
+ignore:
+  - notes.md
+variables:
+  - name: greet
+    default: ciao
+  - name: surname
+    type: string
+`,
+		"shared.txt": "child",
+		"notes.md":   "notes",
+	})
+
+	src := filepath.Join(root, "child")
+	files, err := crawl.NewLocalCrawler(src).Crawl()
+	require.Nil(t, err)
+	data, err := Load("basta.yaml", files)
+	require.Nil(t, err)
+
+	files, data, err = Extend(src, files, data)
+	require.Nil(t, err)
+
+	contents := map[string]string{}
+	for _, f := range files {
+		if f.Path == "basta.yaml" {
+			continue
+		}
+		b, err := ioutil.ReadAll(f.Reader)
+		require.Nil(t, err)
+		contents[f.Path] = string(b)
+	}
+	require.Equal(t, map[string]string{
+		"main.sh":      "echo {{.greet}} {{.name}}",
+		"shared.txt":   "child",
+		"static/a.css": "a {}",
+		"notes.md":     "notes",
+	}, contents)
+
+	require.Equal(t, "", data.Extends)
+	require.Equal(t, []string{"basta.yaml", "notes.md"}, data.Ignore)
+	require.Equal(t, []string{"static/"}, data.PassThrough)
+
+	spec, err := New(data, false)
+	require.Nil(t, err)
+	require.Equal(t, 3, len(spec.Variables))
+	require.Equal(t, "name", spec.Variables[0].name)
+	require.Equal(t, "base description", spec.Variables[0].description.in(""))
+	require.Equal(t, "greet", spec.Variables[1].name)
+	require.Equal(t, "string", *spec.Variables[1].dtype)
+	require.Equal(t, "ciao", spec.Variables[1].defaultVal)
+	require.Equal(t, "surname", spec.Variables[2].name)
+}
+
+func Test_Extend_cycle(t *testing.T) {
+	root, err := ioutil.TempDir("", "extends")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeTestTemplate(t, filepath.Join(root, "a"), map[string]string{"basta.yaml": "extends: ../b\n"})
+	writeTestTemplate(t, filepath.Join(root, "b"), map[string]string{"basta.yaml": "extends: ../a\n"})
+
+	src := filepath.Join(root, "a")
+	files, err := crawl.NewLocalCrawler(src).Crawl()
+	require.Nil(t, err)
+	data, err := Load("basta.yaml", files)
+	require.Nil(t, err)
+
+	_, _, err = Extend(src, files, data)
+	require.NotNil(t, err)
+}
+
+func Test_Extend_patternsSemantics(t *testing.T) {
+	tests := []struct {
+		name  string
+		child string
+		err   bool
+	}{
+		{
+			name:  "newer child",
+			child: "version: 3\nextends: ../base\n",
+			err:   true,
+		},
+		{
+			name:  "newer child keeping the legacy patterns",
+			child: "version: 3\nlegacy-patterns: true\nextends: ../base\n",
+		},
+		{
+			name:  "legacy child",
+			child: "version: 2\nextends: ../base\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "extends")
+			require.Nil(t, err)
+			defer func() { _ = os.RemoveAll(root) }()
+
+			writeTestTemplate(t, filepath.Join(root, "base"), map[string]string{"basta.yaml": "version: 2\nignore:\n  - /docs/\n"})
+			writeTestTemplate(t, filepath.Join(root, "child"), map[string]string{"basta.yaml": tt.child})
+
+			src := filepath.Join(root, "child")
+			files, err := crawl.NewLocalCrawler(src).Crawl()
+			require.Nil(t, err)
+			data, err := Load("basta.yaml", files)
+			require.Nil(t, err)
+
+			_, data, err = Extend(src, files, data)
+			if tt.err {
+				require.NotNil(t, err)
+				require.Contains(t, err.Error(), "legacy-patterns: true")
+				return
+			}
+			require.Nil(t, err)
+			require.True(t, data.LegacyPatterns)
+		})
+	}
+}
+
+func Test_Extend_components(t *testing.T) {
+	root, err := ioutil.TempDir("", "extends")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeTestTemplate(t, filepath.Join(root, "shared", "db"), map[string]string{
+		"basta.yaml": "version: 3\nvariables:\n  - name: host\n    type: string\n",
+	})
+	writeTestTemplate(t, filepath.Join(root, "shared", "base"), map[string]string{
+		"basta.yaml": "version: 3\ncomponents:\n  - name: db\n    src: ../db\n",
+	})
+	writeTestTemplate(t, filepath.Join(root, "services", "child"), map[string]string{
+		"basta.yaml": "version: 3\nextends: ../../shared/base\n",
+	})
+
+	src := filepath.Join(root, "services", "child")
+	files, err := crawl.NewLocalCrawler(src).Crawl()
+	require.Nil(t, err)
+	data, err := Load("basta.yaml", files)
+	require.Nil(t, err)
+	_, data, err = Extend(src, files, data)
+	require.Nil(t, err)
+
+	spec, err := New(data, false)
+	require.Nil(t, err)
+	require.Nil(t, spec.LoadComponents(src, false))
+	require.Equal(t, 1, len(spec.Components))
+	require.Equal(t, "db.host", spec.Components[0].Spec.Variables[0].qualifiedName())
+}
+
+func Test_Extend_error(t *testing.T) {
+	tests := []struct {
+		name   string
+		parent string
+		child  string
+		err    string
+	}{
+		{
+			name:   "other delimiters",
+			parent: "version: 3\n",
+			child:  "version: 3\nextends: ../base\ndelimiters: [\"[[\", \"]]\"]\n",
+			err:    "specification error [delimiters]: can't differ from the ones of",
+		},
+		{
+			name:   "other parent delimiters",
+			parent: "version: 3\ndelimiters: [\"<<\", \">>\"]\n",
+			child:  "version: 3\nextends: ../base\ndelimiters: [\"[[\", \"]]\"]\n",
+			err:    "specification error [delimiters]: can't differ from the ones of",
+		},
+		{
+			name:   "other partials",
+			parent: "version: 3\npartials: partials\n",
+			child:  "version: 3\nextends: ../base\npartials: snippets\n",
+			err:    "specification error [partials]: can't differ from the ones of",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root, err := ioutil.TempDir("", "extends")
+			require.Nil(t, err)
+			defer func() { _ = os.RemoveAll(root) }()
+
+			writeTestTemplate(t, filepath.Join(root, "base"), map[string]string{"basta.yaml": tt.parent})
+			writeTestTemplate(t, filepath.Join(root, "child"), map[string]string{"basta.yaml": tt.child})
+
+			src := filepath.Join(root, "child")
+			files, err := crawl.NewLocalCrawler(src).Crawl()
+			require.Nil(t, err)
+			data, err := Load("basta.yaml", files)
+			require.Nil(t, err)
+
+			_, _, err = Extend(src, files, data)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func Test_Extend_delimiters(t *testing.T) {
+	root, err := ioutil.TempDir("", "extends")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+
+	writeTestTemplate(t, filepath.Join(root, "base"), map[string]string{"basta.yaml": "version: 3\ndelimiters: [\"[[\", \"]]\"]\n"})
+	writeTestTemplate(t, filepath.Join(root, "child"), map[string]string{"basta.yaml": "version: 3\nextends: ../base\n"})
+
+	src := filepath.Join(root, "child")
+	files, err := crawl.NewLocalCrawler(src).Crawl()
+	require.Nil(t, err)
+	data, err := Load("basta.yaml", files)
+	require.Nil(t, err)
+
+	_, data, err = Extend(src, files, data)
+	require.Nil(t, err)
+	require.Equal(t, []string{"[[", "]]"}, data.Delimiters)
+}
+
+func Test_mergeSpecData_metadata(t *testing.T) {
+	parent := &SpecData{Metadata: &Metadata{Name: "base"}}
+
+	merged := mergeSpecData(parent, &SpecData{})
+	require.Equal(t, "base", merged.Metadata.Name)
+
+	merged = mergeSpecData(parent, &SpecData{Metadata: &Metadata{Name: "child"}})
+	require.Equal(t, "child", merged.Metadata.Name)
+}
+
+func Test_mergeRequirements(t *testing.T) {
+	version := ">= 1"
+	merged := mergeRequirements(
+		[]RequirementData{{Name: "go"}, {Name: "docker"}},
+		[]RequirementData{{Name: "protoc"}, {Name: "go", Version: &version}},
+	)
+	require.Equal(t, []RequirementData{{Name: "go", Version: &version}, {Name: "docker"}, {Name: "protoc"}}, merged)
+}
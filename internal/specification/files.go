@@ -0,0 +1,224 @@
+package specification
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/crawl"
+	"copy-basta/internal/load"
+)
+
+var missingKeyOptions = map[string]struct{}{
+	"default": {},
+	"invalid": {},
+	"zero":    {},
+	"error":   {},
+}
+
+// A fileRule applies to the template files matching its path pattern
+type fileRule struct {
+	path string
+	m    Matcher
+	when *expression
+	rule load.Rule
+}
+
+// A Ruler gives the per-file settings set by the file rules.
+// when several rules match a file, the settings of the last ones win
+type Ruler struct {
+	defaults load.Rule
+	rules    []fileRule
+	partials string
+}
+
+func newRuler(data *SpecData) (*Ruler, error) {
+	var defaults load.Rule
+	if data.Delimiters != nil {
+		if !validDelimiters(data.Delimiters) {
+			return nil, fmt.Errorf("specification error [delimiters]: must be a left and a right delimiter")
+		}
+		defaults.Options.Delimiters = data.Delimiters
+	}
+
+	partials := data.Partials
+	if partials != "" {
+		partials = path.Clean(partials)
+		if path.IsAbs(partials) || partials == "." || strings.HasPrefix(partials, "../") {
+			return nil, fmt.Errorf("specification error [partials]: must be a directory of the template (%s)", partials)
+		}
+	}
+
+	var rules []fileRule
+	for _, rd := range data.Files {
+		rule, err := newFileRule(rd, data.LegacyPatterns)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+	return &Ruler{defaults: defaults, rules: rules, partials: partials}, nil
+}
+
+func validDelimiters(delimiters []string) bool {
+	return len(delimiters) == 2 && delimiters[0] != "" && delimiters[1] != ""
+}
+
+func newFileRule(rd FileRuleData, legacyPatterns bool) (*fileRule, error) {
+	if rd.Path == "" {
+		return nil, fmt.Errorf("file rule error [path]: is required")
+	}
+	m, err := NewMatcher([]string{rd.Path}, legacyPatterns)
+	if err != nil {
+		return nil, fmt.Errorf("file rule error [%s]: %s", rd.Path, err.Error())
+	}
+	rule := fileRule{path: rd.Path, m: m}
+
+	if rd.When != nil {
+		when, err := newExpression(rd.Path, *rd.When)
+		if err != nil {
+			return nil, fmt.Errorf("file rule error [%s]: when error: %s", rd.Path, err.Error())
+		}
+		rule.when = when
+	}
+
+	if rd.To != nil {
+		if *rd.To == "" {
+			return nil, fmt.Errorf("file rule error [%s]: to can't be empty", rd.Path)
+		}
+		to := path.Clean(*rd.To)
+		if path.IsAbs(to) || to == ".." || strings.HasPrefix(to, "../") {
+			return nil, fmt.Errorf("file rule error [%s]: to must be a path inside the generated project (%s)", rd.Path, *rd.To)
+		}
+		rule.rule.To = *rd.To
+	}
+
+	if rd.Mode != nil {
+		mode, err := strconv.ParseUint(*rd.Mode, 8, 32)
+		if err != nil || mode == 0 || mode > uint64(os.ModePerm) {
+			return nil, fmt.Errorf("file rule error [%s]: mode (%s) is not a valid octal file mode", rd.Path, *rd.Mode)
+		}
+		rule.rule.Mode = os.FileMode(mode)
+	}
+
+	if rd.Delimiters != nil {
+		if !validDelimiters(rd.Delimiters) {
+			return nil, fmt.Errorf("file rule error [%s]: delimiters must be a left and a right delimiter", rd.Path)
+		}
+		rule.rule.Options.Delimiters = rd.Delimiters
+	}
+
+	if rd.MissingKey != nil {
+		if _, ok := missingKeyOptions[*rd.MissingKey]; !ok {
+			return nil, fmt.Errorf("file rule error [%s]: unknown missingkey option (%s)", rd.Path, *rd.MissingKey)
+		}
+		rule.rule.Options.MissingKey = *rd.MissingKey
+	}
+
+	return &rule, nil
+}
+
+// validateFields checks that the rule expressions only reference declared names
+func (r *fileRule) validateFields(declared map[string]struct{}) error {
+	if r.when == nil {
+		return nil
+	}
+	for _, field := range r.when.fields() {
+		if _, ok := declared[field]; !ok {
+			return fmt.Errorf("file rule error [%s]: references undeclared variable `%s`", r.path, field)
+		}
+	}
+	return nil
+}
+
+func (r *Ruler) Rule(s string) load.Rule {
+	rule := r.defaults
+	for _, fr := range r.rules {
+		if !fr.m.Match(s) {
+			continue
+		}
+		if fr.rule.To != "" {
+			rule.To = fr.rule.To
+		}
+		if fr.rule.Mode != 0 {
+			rule.Mode = fr.rule.Mode
+		}
+		if fr.rule.Options.Delimiters != nil {
+			rule.Options.Delimiters = fr.rule.Options.Delimiters
+		}
+		if fr.rule.Options.MissingKey != "" {
+			rule.Options.MissingKey = fr.rule.Options.MissingKey
+		}
+	}
+	rule.Partial = r.partial(s)
+	return rule
+}
+
+// partial returns the name of the partial defined by the file: its path inside
+// the partials directory, without extension (`partials/license/mit.tmpl` defines `license/mit`)
+func (r *Ruler) partial(s string) string {
+	if r.partials == "" || !strings.HasPrefix(s, r.partials+"/") {
+		return ""
+	}
+	name := strings.TrimPrefix(s, r.partials+"/")
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+// FilesIgnorer returns the Ignorer to use with the collected input. on top of the ignored patterns,
+// it ignores the files matched by the rules whose `when` expression is false
+func (s *Spec) FilesIgnorer(input common.InputVariables) (*Ignorer, error) {
+	ignorer := s.Ignorer
+	for _, rule := range s.Ruler.rules {
+		if rule.when == nil {
+			continue
+		}
+		included, err := rule.when.isTrue(input)
+		if err != nil {
+			return nil, fmt.Errorf("file rule error [%s]: when error: %s", rule.path, err.Error())
+		}
+		if !included {
+			ignorer = ignorer.with(rule.m)
+		}
+	}
+	return ignorer, nil
+}
+
+// conditional tells whether a rule with a `when` expression matches the file
+func (r *Ruler) conditional(s string) bool {
+	for _, fr := range r.rules {
+		if fr.when != nil && fr.m.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckFiles checks the file rules against the crawled files, and the ones of the components, before the
+// input is collected: two files always included can't be written to the same `to`.
+// the files a `when` expression can exclude are checked as they are loaded
+func (s *Spec) CheckFiles(files []crawl.File) error {
+	renamed := map[string]string{}
+	for _, f := range files {
+		if s.Ignorer.Ignore(f.Path) || s.Ruler.conditional(f.Path) {
+			continue
+		}
+		to := s.Ruler.Rule(f.Path).To
+		if to == "" {
+			continue
+		}
+		if other, found := renamed[to]; found {
+			return fmt.Errorf("file rule error: `%s` and `%s` are both written to `%s`, a file rule `to` must match a single file", other, f.Path, to)
+		}
+		renamed[to] = f.Path
+	}
+
+	for _, c := range s.Components {
+		if err := c.Spec.CheckFiles(c.Files); err != nil {
+			return fmt.Errorf("component error [%s]: %s", c.Name, err.Error())
+		}
+	}
+	return nil
+}
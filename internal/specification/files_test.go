@@ -0,0 +1,235 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/crawl"
+	"copy-basta/internal/load"
+)
+
+const filesYAML = `---
+version: 2
+ignore:
+  - basta.yaml
+variables:
+  - name: useDatabase
+    type: boolean
+  - name: ci
+    type: string
+files:
+  - path: db/
+    when: "{{ .useDatabase }}"
+  - path: .travis.yml
+    when: "{{ eq .ci \"travis\" }}"
+  - path: README.md
+`
+
+func Test_Spec_FilesIgnorer(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(filesYAML), false)
+	require.Nil(t, err)
+
+	tests := []struct {
+		name    string
+		input   common.InputVariables
+		ignored []string
+		kept    []string
+	}{
+		{
+			name:    "all included",
+			input:   common.InputVariables{"useDatabase": true, "ci": "travis"},
+			ignored: []string{"basta.yaml"},
+			kept:    []string{"db/schema.sql", "db/migrations/001.sql", ".travis.yml", "README.md"},
+		},
+		{
+			name:    "conditions false",
+			input:   common.InputVariables{"useDatabase": false, "ci": "github"},
+			ignored: []string{"basta.yaml", "db/schema.sql", "db/migrations/001.sql", ".travis.yml"},
+			kept:    []string{"README.md", "main.go"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ignorer, err := spec.FilesIgnorer(tt.input)
+			require.Nil(t, err)
+			for _, p := range tt.ignored {
+				require.True(t, ignorer.Ignore(p), p)
+			}
+			for _, p := range tt.kept {
+				require.False(t, ignorer.Ignore(p), p)
+			}
+		})
+	}
+
+	// the spec ignorer is not altered
+	require.False(t, spec.Ignorer.Ignore("db/schema.sql"))
+}
+
+func Test_Ruler_Rule(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(`---
+version: 2
+files:
+  - path: main.go
+    to: "cmd/{{ .name }}/main.go"
+  - path: scripts/
+    mode: "0755"
+  - path: "*.yml"
+    delimiters: ["[[", "]]"]
+  - path: "ci.yml"
+    missingkey: zero
+    delimiters: ["<%", "%>"]
+`), false)
+	require.Nil(t, err)
+
+	tests := []struct {
+		path string
+		rule load.Rule
+	}{
+		{path: "README.md", rule: load.Rule{}},
+		{path: "main.go", rule: load.Rule{To: "cmd/{{ .name }}/main.go"}},
+		{path: "scripts/build.sh", rule: load.Rule{Mode: 0755}},
+		{path: "chart.yml", rule: load.Rule{Options: load.TemplateOptions{Delimiters: []string{"[[", "]]"}}}},
+		{path: "ci.yml", rule: load.Rule{Options: load.TemplateOptions{Delimiters: []string{"<%", "%>"}, MissingKey: "zero"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.rule, spec.Ruler.Rule(tt.path))
+		})
+	}
+}
+
+func Test_Ruler_Rule_delimiters(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(`---
+version: 2
+delimiters: ["[[", "]]"]
+files:
+  - path: "*.go"
+    delimiters: ["{{", "}}"]
+`), false)
+	require.Nil(t, err)
+
+	require.Equal(t, []string{"[[", "]]"}, spec.Ruler.Rule("chart.yml").Options.Delimiters)
+	require.Equal(t, []string{"{{", "}}"}, spec.Ruler.Rule("main.go").Options.Delimiters)
+}
+
+func Test_Ruler_Rule_partials(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader("version: 2\npartials: partials/\n"), false)
+	require.Nil(t, err)
+
+	require.Equal(t, "header", spec.Ruler.Rule("partials/header.tmpl").Partial)
+	require.Equal(t, "license/mit", spec.Ruler.Rule("partials/license/mit.txt").Partial)
+	require.Equal(t, "", spec.Ruler.Rule("main.go").Partial)
+	require.Equal(t, "", spec.Ruler.Rule("partials.go").Partial)
+}
+
+func Test_newFileRules_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "missing path",
+			yml:  "version: 2\nfiles:\n  - when: \"true\"\n",
+			err:  "file rule error [path]: is required",
+		},
+		{
+			name: "undeclared variable",
+			yml:  "version: 2\nfiles:\n  - path: db/\n    when: \"{{ .useDatabase }}\"\n",
+			err:  "file rule error [db/]: references undeclared variable `useDatabase`",
+		},
+		{
+			name: "invalid expression",
+			yml:  "version: 2\nfiles:\n  - path: db/\n    when: \"{{ .useDatabase \"\n",
+			err:  "file rule error [db/]: when error",
+		},
+		{
+			name: "invalid mode",
+			yml:  "version: 2\nfiles:\n  - path: scripts/\n    mode: \"0999\"\n",
+			err:  "file rule error [scripts/]: mode (0999) is not a valid octal file mode",
+		},
+		{
+			name: "invalid delimiters",
+			yml:  "version: 2\nfiles:\n  - path: \"*.yml\"\n    delimiters: [\"[[\"]\n",
+			err:  "file rule error [*.yml]: delimiters must be a left and a right delimiter",
+		},
+		{
+			name: "invalid spec delimiters",
+			yml:  "version: 2\ndelimiters: [\"[[\", \"\"]\n",
+			err:  "specification error [delimiters]: must be a left and a right delimiter",
+		},
+		{
+			name: "partials outside the template",
+			yml:  "version: 2\npartials: ../partials\n",
+			err:  "specification error [partials]: must be a directory of the template (../partials)",
+		},
+		{
+			name: "to outside the project",
+			yml:  "version: 2\nfiles:\n  - path: bashrc\n    to: ../../.bashrc\n",
+			err:  "file rule error [bashrc]: to must be a path inside the generated project (../../.bashrc)",
+		},
+		{
+			name: "absolute to",
+			yml:  "version: 2\nfiles:\n  - path: bashrc\n    to: /root/.bashrc\n",
+			err:  "file rule error [bashrc]: to must be a path inside the generated project (/root/.bashrc)",
+		},
+		{
+			name: "unknown missingkey",
+			yml:  "version: 2\nfiles:\n  - path: \"*.yml\"\n    missingkey: ignore\n",
+			err:  "file rule error [*.yml]: unknown missingkey option (ignore)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func Test_Spec_CheckFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "single file renamed",
+			yml:  "version: 3\nfiles:\n  - path: LICENSE.mit\n    to: LICENSE\n",
+		},
+		{
+			name: "files excluded by when",
+			yml:  "version: 3\nvariables:\n  - name: mit\n    type: boolean\nfiles:\n  - path: LICENSE.*\n    to: LICENSE\n  - path: LICENSE.mit\n    when: \"{{ .mit }}\"\n  - path: LICENSE.apache\n    when: \"{{ not .mit }}\"\n",
+		},
+		{
+			name: "ignored file",
+			yml:  "version: 3\nignore:\n  - LICENSE.apache\nfiles:\n  - path: LICENSE.*\n    to: LICENSE\n",
+		},
+		{
+			name: "files written to the same path",
+			yml:  "version: 3\nfiles:\n  - path: LICENSE.*\n    to: LICENSE\n",
+			err:  "file rule error: `LICENSE.apache` and `LICENSE.mit` are both written to `LICENSE`, a file rule `to` must match a single file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			files := []crawl.File{
+				{Path: "LICENSE.apache", Reader: strings.NewReader("apache")},
+				{Path: "LICENSE.mit", Reader: strings.NewReader("mit")},
+			}
+			err = spec.CheckFiles(files)
+			if tt.err == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Equal(t, tt.err, err.Error())
+		})
+	}
+}
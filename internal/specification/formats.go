@@ -0,0 +1,166 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"go/token"
+	"net"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"copy-basta/internal/common/semver"
+)
+
+/*
+String formats are the open-api ones (https://swagger.io/docs/specification/data-models/data-types/#format)
+plus a few go specific ones
+*/
+
+const (
+	formatEmail        = "email"
+	formatURI          = "uri"
+	formatHostname     = "hostname"
+	formatIPv4         = "ipv4"
+	formatDate         = "date"
+	formatDateTime     = "date-time"
+	formatUUID         = "uuid"
+	formatGoModulePath = "go-module-path"
+	formatGoIdentifier = "go-identifier"
+	formatSemver       = "semver"
+)
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = time.RFC3339
+)
+
+type stringFormat struct {
+	check   func(string) error
+	example string
+	// parse, when set, converts the string into the value given to the templates
+	parse func(string) (interface{}, error)
+}
+
+var stringFormats = map[string]stringFormat{
+	formatEmail: {
+		check:   checkEmail,
+		example: "chef@pasta.it",
+	},
+	formatURI: {
+		check:   checkURI,
+		example: "https://pasta.it/recipes",
+	},
+	formatHostname: {
+		check:   checkHostname,
+		example: "api.pasta.it",
+	},
+	formatIPv4: {
+		check:   checkIPv4,
+		example: "192.168.0.1",
+	},
+	formatDate: {
+		check:   func(s string) error { _, err := time.Parse(dateLayout, s); return err },
+		example: "2020-04-25",
+		parse:   func(s string) (interface{}, error) { return time.Parse(dateLayout, s) },
+	},
+	formatDateTime: {
+		check:   func(s string) error { _, err := time.Parse(dateTimeLayout, s); return err },
+		example: "2020-04-25T18:30:00Z",
+		parse:   func(s string) (interface{}, error) { return time.Parse(dateTimeLayout, s) },
+	},
+	formatUUID: {
+		check:   checkRegexp(uuidRegexp),
+		example: "123e4567-e89b-12d3-a456-426614174000",
+	},
+	formatGoModulePath: {
+		check:   checkGoModulePath,
+		example: "github.com/acciaioli/copy-basta",
+	},
+	formatGoIdentifier: {
+		check:   checkGoIdentifier,
+		example: "pastaRecipe",
+	},
+	formatSemver: {
+		check:   func(s string) error { _, err := semver.Parse(s); return err },
+		example: "1.4.2",
+	},
+}
+
+var (
+	uuidRegexp            = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnameLabelRegexp   = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]{0,61}[a-zA-Z0-9])?$`)
+	goModuleElementRegexp = regexp.MustCompile(`^[a-zA-Z0-9._~-]+$`)
+)
+
+func checkRegexp(r *regexp.Regexp) func(string) error {
+	return func(s string) error {
+		if !r.MatchString(s) {
+			return fmt.Errorf("`%s` does not match `%s`", s, r.String())
+		}
+		return nil
+	}
+}
+
+func checkEmail(s string) error {
+	address, err := mail.ParseAddress(s)
+	if err != nil {
+		return err
+	}
+	if address.Address != s {
+		return errors.New("expected a bare email address")
+	}
+	return nil
+}
+
+func checkURI(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" {
+		return errors.New("missing scheme")
+	}
+	return nil
+}
+
+func checkHostname(s string) error {
+	if len(s) > 253 {
+		return errors.New("longer than 253 characters")
+	}
+	for _, label := range strings.Split(strings.TrimSuffix(s, "."), ".") {
+		if !hostnameLabelRegexp.MatchString(label) {
+			return fmt.Errorf("invalid label `%s`", label)
+		}
+	}
+	return nil
+}
+
+func checkIPv4(s string) error {
+	ip := net.ParseIP(s)
+	if ip == nil || ip.To4() == nil || strings.Contains(s, ":") {
+		return errors.New("not an ipv4 address")
+	}
+	return nil
+}
+
+func checkGoModulePath(s string) error {
+	if s == "" || strings.HasPrefix(s, "-") {
+		return errors.New("invalid module path")
+	}
+	for _, element := range strings.Split(s, "/") {
+		if element == "." || element == ".." || !goModuleElementRegexp.MatchString(element) {
+			return fmt.Errorf("invalid path element `%s`", element)
+		}
+	}
+	return nil
+}
+
+func checkGoIdentifier(s string) error {
+	if !token.IsIdentifier(s) {
+		return errors.New("not a go identifier")
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func newFormatVar(format string) Variable {
+	v := newSpecVar(openAPIString)
+	v.format = &format
+	return v
+}
+
+func Test_stringFormats(t *testing.T) {
+	tests := []struct {
+		format  string
+		valid   []string
+		invalid []string
+	}{
+		{
+			format:  formatEmail,
+			valid:   []string{"chef@pasta.it"},
+			invalid: []string{"chef", "Chef <chef@pasta.it>"},
+		},
+		{
+			format:  formatURI,
+			valid:   []string{"https://pasta.it/recipes", "postgres://db:5432/pasta"},
+			invalid: []string{"pasta.it", "/recipes"},
+		},
+		{
+			format:  formatHostname,
+			valid:   []string{"localhost", "api.pasta.it"},
+			invalid: []string{"-pasta.it", "pasta_it", "pasta..it"},
+		},
+		{
+			format:  formatIPv4,
+			valid:   []string{"127.0.0.1"},
+			invalid: []string{"::1", "256.0.0.1", "localhost"},
+		},
+		{
+			format:  formatDate,
+			valid:   []string{"2020-04-25"},
+			invalid: []string{"25/04/2020", "2020-04-25T18:30:00Z"},
+		},
+		{
+			format:  formatDateTime,
+			valid:   []string{"2020-04-25T18:30:00Z", "2020-04-25T18:30:00+01:00"},
+			invalid: []string{"2020-04-25"},
+		},
+		{
+			format:  formatUUID,
+			valid:   []string{"123e4567-e89b-12d3-a456-426614174000"},
+			invalid: []string{"123e4567e89b12d3a456426614174000"},
+		},
+		{
+			format:  formatGoModulePath,
+			valid:   []string{"copy-basta", "github.com/acciaioli/copy-basta"},
+			invalid: []string{"", "github.com/", "github.com/../x", "github.com/a b"},
+		},
+		{
+			format:  formatGoIdentifier,
+			valid:   []string{"pasta", "_pasta2", "Pasta"},
+			invalid: []string{"2pasta", "pasta-recipe", "func"},
+		},
+		{
+			format:  formatSemver,
+			valid:   []string{"1.4.2", "v0.1.0-rc.1"},
+			invalid: []string{"1.4", "latest"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			v := newFormatVar(tt.format)
+			for _, value := range tt.valid {
+				require.Nil(t, v.valueOk(value), value)
+			}
+			for _, value := range tt.invalid {
+				require.NotNil(t, v.valueOk(value), value)
+			}
+			require.Contains(t, v.Help(), stringFormats[tt.format].example)
+			require.Nil(t, v.valueOk(stringFormats[tt.format].example))
+		})
+	}
+}
+
+func Test_Variables_dateFormats(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: release
+    type: string
+    format: date
+  - name: deadline
+    type: string
+    format: date-time
+    default: 2020-04-25T18:30:00Z
+`)
+
+	input, err := vars.fromInput(common.InputVariables{"release": "2020-04-20"})
+	require.Nil(t, err)
+	require.Equal(t, time.Date(2020, 4, 20, 0, 0, 0, 0, time.UTC), input["release"])
+	require.Equal(t, time.Date(2020, 4, 25, 18, 30, 0, 0, time.UTC), input["deadline"])
+}
+
+func Test_newVariables_format_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+	}{
+		{
+			name: "unknown format",
+			yml: `
+variables:
+  - name: x
+    type: string
+    format: pasta
+`,
+		},
+		{
+			name: "not a string",
+			yml: `
+variables:
+  - name: x
+    type: integer
+    format: date
+`,
+		},
+		{
+			name: "invalid default",
+			yml: `
+variables:
+  - name: x
+    type: string
+    format: email
+    default: nope
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NotNil(t, newTestVariablesError(t, tt.yml))
+		})
+	}
+}
+
+func Test_Variables_format_prompt(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: email
+    type: string
+    format: email
+`)
+
+	input, err := vars.fromReader(newLineReader(strings.NewReader("chef\nchef@pasta.it\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"email": "chef@pasta.it"}, input)
+
+	_, err = vars.fromReader(newLineReader(strings.NewReader("a\nb\nc\n")), nil)
+	require.NotNil(t, err)
+}
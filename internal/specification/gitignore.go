@@ -0,0 +1,176 @@
+package specification
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+// A Matcher matches template file paths against patterns
+type Matcher interface {
+	Match(string) bool
+}
+
+// NewMatcher returns a gitignore patterns Matcher, or a legacy PatternMatcher
+func NewMatcher(patterns []string, legacy bool) (Matcher, error) {
+	if legacy {
+		pm, err := NewPatternMatcher(patterns)
+		if err != nil {
+			return nil, err
+		}
+		return pm, nil
+	}
+	gm, err := NewGitignoreMatcher(patterns)
+	if err != nil {
+		return nil, err
+	}
+	return gm, nil
+}
+
+// GitignoreMatcher matches paths with the .gitignore patterns semantics:
+// patterns without a slash match at any depth, `**` matches any number of directories,
+// patterns ending with a slash only match directories and `!` re-includes paths excluded by previous patterns.
+// as in git, a file can't be re-included when one of its parent directories is excluded
+type GitignoreMatcher struct {
+	rules []gitignoreRule
+}
+
+type gitignoreRule struct {
+	re      *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+func NewGitignoreMatcher(patterns []string) (*GitignoreMatcher, error) {
+	gm := GitignoreMatcher{}
+	for _, pattern := range patterns {
+		rule, err := newGitignoreRule(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern (%s): %s", pattern, err.Error())
+		}
+		if rule != nil {
+			gm.rules = append(gm.rules, *rule)
+		}
+	}
+	return &gm, nil
+}
+
+// newGitignoreRule parses a pattern, it returns nil for blank lines and comments
+func newGitignoreRule(pattern string) (*gitignoreRule, error) {
+	pattern = strings.TrimRight(pattern, " ")
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return nil, nil
+	}
+
+	rule := gitignoreRule{}
+	if strings.HasPrefix(pattern, "!") {
+		rule.negate = true
+		pattern = pattern[1:]
+	} else if strings.HasPrefix(pattern, `\!`) || strings.HasPrefix(pattern, `\#`) {
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	if pattern == "" {
+		return nil, fmt.Errorf("empty pattern")
+	}
+
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+
+	expr := strings.Builder{}
+	expr.WriteString("^")
+	if !anchored {
+		expr.WriteString("(?:.*/)?")
+	}
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		last := i == len(segments)-1
+		if segment == "**" {
+			if last {
+				expr.WriteString(".*")
+			} else {
+				expr.WriteString("(?:.*/)?")
+			}
+			continue
+		}
+		s, err := globToRegexp(segment)
+		if err != nil {
+			return nil, err
+		}
+		expr.WriteString(s)
+		if !last {
+			expr.WriteString("/")
+		}
+	}
+	expr.WriteString("$")
+
+	re, err := regexp.Compile(expr.String())
+	if err != nil {
+		return nil, err
+	}
+	rule.re = re
+	return &rule, nil
+}
+
+// globToRegexp converts a path segment glob to a regular expression
+func globToRegexp(glob string) (string, error) {
+	expr := strings.Builder{}
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			expr.WriteString("[^/]*")
+		case '?':
+			expr.WriteString("[^/]")
+		case '\\':
+			i++
+			if i == len(glob) {
+				return "", fmt.Errorf("trailing backslash")
+			}
+			expr.WriteString(regexp.QuoteMeta(string(glob[i])))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("unterminated character class")
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			expr.WriteString("[" + strings.Replace(class, `\`, `\\`, -1) + "]")
+			i += end + 1
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return expr.String(), nil
+}
+
+func (gm *GitignoreMatcher) Match(s string) bool {
+	s = strings.Trim(path.Clean(s), "/")
+	segments := strings.Split(s, "/")
+	for i := 1; i < len(segments); i++ {
+		if gm.match(strings.Join(segments[:i], "/"), true) {
+			return true
+		}
+	}
+	return gm.match(s, false)
+}
+
+// match returns whether the last rule matching the path excludes it
+func (gm *GitignoreMatcher) match(s string, isDir bool) bool {
+	matched := false
+	for _, rule := range gm.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.re.MatchString(s) {
+			matched = !rule.negate
+		}
+	}
+	return matched
+}
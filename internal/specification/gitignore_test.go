@@ -0,0 +1,61 @@
+package specification
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GitignoreMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name     string
+		patterns []string
+		filepath string
+		matched  bool
+	}{
+		{name: "file", patterns: []string{"my-file.go"}, filepath: "my-file.go", matched: true},
+		{name: "unanchored file nested", patterns: []string{"my-file.go"}, filepath: "a/b/my-file.go", matched: true},
+		{name: "unanchored glob nested", patterns: []string{"*.log"}, filepath: "logs/a.log", matched: true},
+		{name: "glob not matched", patterns: []string{"*.log"}, filepath: "logs/a.log.txt", matched: false},
+		{name: "anchored at root", patterns: []string{"/main.go"}, filepath: "main.go", matched: true},
+		{name: "anchored not nested", patterns: []string{"/main.go"}, filepath: "cmd/main.go", matched: false},
+		{name: "anchored with slash", patterns: []string{"cmd/*.go"}, filepath: "cmd/main.go", matched: true},
+		{name: "anchored with slash not nested", patterns: []string{"cmd/*.go"}, filepath: "x/cmd/main.go", matched: false},
+		{name: "star doesn't cross dirs", patterns: []string{"cmd/*"}, filepath: "cmd/a/main.go", matched: true},
+		{name: "dir", patterns: []string{"build/"}, filepath: "build/out/bin", matched: true},
+		{name: "dir nested", patterns: []string{"build/"}, filepath: "x/build/bin", matched: true},
+		{name: "dir only", patterns: []string{"build/"}, filepath: "build", matched: false},
+		{name: "leading double star", patterns: []string{"**/testdata"}, filepath: "a/b/testdata/x.json", matched: true},
+		{name: "trailing double star", patterns: []string{"docs/**"}, filepath: "docs/a/b.md", matched: true},
+		{name: "trailing double star not the dir", patterns: []string{"docs/**"}, filepath: "docs", matched: false},
+		{name: "middle double star", patterns: []string{"a/**/b.go"}, filepath: "a/b.go", matched: true},
+		{name: "middle double star nested", patterns: []string{"a/**/b.go"}, filepath: "a/x/y/b.go", matched: true},
+		{name: "negation", patterns: []string{"*.md", "!README.md"}, filepath: "README.md", matched: false},
+		{name: "negation other files", patterns: []string{"*.md", "!README.md"}, filepath: "CHANGELOG.md", matched: true},
+		{name: "negation order", patterns: []string{"!README.md", "*.md"}, filepath: "README.md", matched: true},
+		{name: "negation in excluded dir", patterns: []string{"docs/", "!docs/README.md"}, filepath: "docs/README.md", matched: true},
+		{name: "negation of dir contents", patterns: []string{"docs/*", "!docs/README.md"}, filepath: "docs/README.md", matched: false},
+		{name: "comment", patterns: []string{"# main.go"}, filepath: "main.go", matched: false},
+		{name: "escaped", patterns: []string{`\#main.go`}, filepath: "#main.go", matched: true},
+		{name: "character class", patterns: []string{"file[0-9].txt"}, filepath: "file1.txt", matched: true},
+		{name: "negated character class", patterns: []string{"file[!0-9].txt"}, filepath: "file1.txt", matched: false},
+		{name: "question mark", patterns: []string{"file?.txt"}, filepath: "fileA.txt", matched: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gm, err := NewGitignoreMatcher(tt.patterns)
+			require.Nil(t, err)
+			require.Equal(t, tt.matched, gm.Match(tt.filepath))
+		})
+	}
+}
+
+func Test_NewGitignoreMatcher_error(t *testing.T) {
+	for _, pattern := range []string{"file[0-9.txt", `file\`, "!"} {
+		t.Run(pattern, func(t *testing.T) {
+			_, err := NewGitignoreMatcher([]string{pattern})
+			require.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+
+	"copy-basta/internal/common"
+)
+
+// A Group is a section of related variables, prompted together
+type Group struct {
+	name        string
+	title       Text
+	description *Text
+	// ask is a yes/no question. the group variables are skipped when answered no
+	ask  *Text
+	lang string
+}
+
+// assignGroups validates the groups and assigns them to their variables. it returns the variables
+// reordered so that the ones of each group are prompted together
+func assignGroups(groupsData []GroupData, vars Variables, varsData []VariableData) (Variables, error) {
+	groups := map[string]*Group{}
+	for _, gd := range groupsData {
+		if gd.Name == "" {
+			return nil, fmt.Errorf("group error [name]: is required")
+		}
+		if _, ok := groups[gd.Name]; ok {
+			return nil, fmt.Errorf("group error [%s]: name is already used", gd.Name)
+		}
+		g := Group{name: gd.Name, title: *newText(gd.Name), description: gd.Description, ask: gd.Ask}
+		if gd.Title != nil {
+			g.title = *gd.Title
+		}
+		groups[gd.Name] = &g
+	}
+
+	for _, vd := range varsData {
+		if vd.Group == nil {
+			continue
+		}
+		g, ok := groups[*vd.Group]
+		if !ok {
+			return nil, fmt.Errorf("variable error [group]: %s is not a declared group", *vd.Group)
+		}
+		v := vars.get(vd.Name)
+		if g.ask != nil && !v.skippable() {
+			return nil, fmt.Errorf("group error [%s]: `%s` is required, it needs a default (or to be optional) to be skipped", g.name, vd.Name)
+		}
+		v.group = g
+	}
+	if len(groups) == 0 {
+		return vars, nil
+	}
+	return groupVariables(vars)
+}
+
+// skippable tells whether the variable gets a value when its group is skipped
+func (v *Variable) skippable() bool {
+	return v.optional || v.defaultVal != nil || v.defaultTmpl != nil || v.value != nil
+}
+
+// groupVariables orders the variables so that the ones of each group are prompted together.
+// each group is placed, as a whole, once the variables its members depend on are placed,
+// keeping the dependencies order. the groups depending on each other through other variables are rejected
+func groupVariables(vars Variables) (Variables, error) {
+	var units []Variables
+	index := map[*Group]int{}
+	for _, v := range vars {
+		if v.group == nil {
+			units = append(units, Variables{v})
+			continue
+		}
+		i, ok := index[v.group]
+		if !ok {
+			i = len(units)
+			index[v.group] = i
+			units = append(units, nil)
+		}
+		units[i] = append(units[i], v)
+	}
+
+	var sorted Variables
+	placed := map[string]struct{}{}
+	for len(units) > 0 {
+		next := -1
+		for i, unit := range units {
+			if vars.unitReady(unit, placed) {
+				next = i
+				break
+			}
+		}
+		if next < 0 {
+			g := units[0][0].group
+			for _, unit := range units {
+				if unit[0].group != nil {
+					g = unit[0].group
+					break
+				}
+			}
+			return nil, fmt.Errorf("group error [%s]: its variables can't be prompted together because of their dependencies", g.name)
+		}
+		for _, v := range units[next] {
+			sorted = append(sorted, v)
+			placed[v.name] = struct{}{}
+		}
+		units = append(units[:next], units[next+1:]...)
+	}
+	return sorted, nil
+}
+
+// unitReady tells whether the variables the unit depends on, out of the unit, are placed
+func (vars Variables) unitReady(unit Variables, placed map[string]struct{}) bool {
+	members := map[string]struct{}{}
+	for _, v := range unit {
+		members[v.name] = struct{}{}
+	}
+	for _, v := range unit {
+		for _, path := range v.dependencies() {
+			for _, name := range vars.resolve(path) {
+				_, member := members[name]
+				_, ok := placed[name]
+				if !member && !ok {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// groupTranslations returns the translatable texts of the groups, by field
+func groupTranslations(groupsData []GroupData) map[string]*Text {
+	texts := map[string]*Text{}
+	for _, gd := range groupsData {
+		texts["groups."+gd.Name+".title"] = gd.Title
+		texts["groups."+gd.Name+".description"] = gd.Description
+		texts["groups."+gd.Name+".ask"] = gd.Ask
+	}
+	return texts
+}
+
+func (g *Group) header() string {
+	sBuilder := strings.Builder{}
+	sBuilder.WriteString("\n")
+	sBuilder.WriteString(common.ColoredFormat(common.ColorMagenta, common.TextFormatBold, common.BGColorNone, g.title.in(g.lang)))
+	sBuilder.WriteString("\n")
+	if g.description != nil {
+		sBuilder.WriteString(common.ColoredFormat(common.ColorMagenta, common.TextFormatNormal, common.BGColorNone, g.description.in(g.lang)))
+		sBuilder.WriteString("\n")
+	}
+	return sBuilder.String()
+}
+
+// sections tracks the groups while prompting, printing a header when a group starts
+// and asking the group question once
+type sections struct {
+	current *Group
+	answers map[*Group]bool
+}
+
+func newSections() *sections {
+	return &sections{answers: map[*Group]bool{}}
+}
+
+// enter enters the variable group, it returns false when the group is skipped
+func (s *sections) enter(r *lineReader, g *Group) (bool, error) {
+	if g == nil {
+		s.current = nil
+		return true, nil
+	}
+	if answer, ok := s.answers[g]; ok {
+		if answer && g != s.current {
+			fmt.Print(g.header())
+		}
+		s.current = g
+		return answer, nil
+	}
+
+	s.current = g
+	fmt.Print(g.header())
+	answer := true
+	if g.ask != nil {
+		var err error
+		answer, err = askYesNo(r, g.ask.in(g.lang))
+		if err != nil {
+			return false, err
+		}
+	}
+	s.answers[g] = answer
+	return answer, nil
+}
+
+func askYesNo(r *lineReader, question string) (bool, error) {
+	qMark := common.ColoredFormat(common.ColorOrange, common.TextFormatBold, common.BGColorNone, "?")
+	for {
+		fmt.Printf("%s %s [y/N]    ", qMark, question)
+		answer, err := r.readLine(false)
+		fmt.Print("\n")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			return false, nil
+		}
+		fmt.Println("answer y or n")
+	}
+}
@@ -0,0 +1,149 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+const groupsYAML = `---
+version: 3
+groups:
+  - name: service
+    title: Service
+    description: The service basics
+  - name: observability
+    title: Observability
+    ask: Configure observability?
+variables:
+  - name: name
+    type: string
+    group: service
+  - name: tracing
+    type: boolean
+    default: true
+    group: observability
+  - name: metricsPort
+    type: integer
+    default: 9090
+    group: observability
+  - name: owner
+    type: string
+`
+
+func Test_Spec_groups(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		expected common.InputVariables
+	}{
+		{
+			name:     "group skipped",
+			stdin:    "api\n\nvasco\n",
+			expected: common.InputVariables{"name": "api", "tracing": true, "metricsPort": 9090, "owner": "vasco"},
+		},
+		{
+			name:     "group asked",
+			stdin:    "api\nmaybe\ny\nfalse\n9100\nvasco\n",
+			expected: common.InputVariables{"name": "api", "tracing": false, "metricsPort": 9100, "owner": "vasco"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(groupsYAML), false)
+			require.Nil(t, err)
+
+			input, err := spec.fromReader(newLineReader(strings.NewReader(tt.stdin)), nil)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, input)
+		})
+	}
+
+	// groups are not asked with input files
+	spec, err := newFromReader(strings.NewReader(groupsYAML), false)
+	require.Nil(t, err)
+	input, err := spec.fromInput(common.InputVariables{"name": "api", "metricsPort": 9100, "owner": "vasco"})
+	require.Nil(t, err)
+	require.Equal(t, 9100, input["metricsPort"])
+}
+
+func Test_Spec_groups_order(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+groups:
+  - name: db
+variables:
+  - name: dbHost
+    type: string
+    group: db
+  - name: name
+    type: string
+  - name: dbName
+    type: string
+    default: "{{ .name }}"
+    group: db
+  - name: dbPort
+    type: integer
+    group: db
+`), false)
+	require.Nil(t, err)
+
+	var names []string
+	for _, v := range spec.Variables {
+		names = append(names, v.name)
+	}
+	require.Equal(t, []string{"name", "dbHost", "dbName", "dbPort"}, names)
+}
+
+func Test_assignGroups_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "undeclared group",
+			yml:  "version: 3\nvariables:\n  - name: a\n    type: string\n    group: misc\n",
+			err:  "variable error [group]: misc is not a declared group",
+		},
+		{
+			name: "required variable of an asked group",
+			yml:  "version: 3\ngroups:\n  - name: misc\n    ask: misc?\nvariables:\n  - name: a\n    type: string\n    group: misc\n",
+			err:  "group error [misc]: `a` is required, it needs a default (or to be optional) to be skipped",
+		},
+		{
+			name: "interleaved by dependencies",
+			yml: `version: 3
+groups:
+  - name: db
+variables:
+  - name: host
+    type: string
+    group: db
+  - name: name
+    type: string
+    default: "{{ .host }}"
+  - name: dsn
+    type: string
+    value: "{{ .host }}/{{ .name }}"
+    group: db
+`,
+			err: "group error [db]: its variables can't be prompted together because of their dependencies",
+		},
+		{
+			name: "duplicated group",
+			yml:  "version: 3\ngroups:\n  - name: misc\n  - name: misc\n",
+			err:  "group error [misc]: name is already used",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Equal(t, tt.err, err.Error())
+		})
+	}
+}
@@ -1,18 +1,29 @@
 package specification
 
 type Ignorer struct {
-	pm *PatternMatcher
+	ms []Matcher
 }
 
-func NewIgnorer(patterns []string) (*Ignorer, error) {
-	pm, err := NewPatternMatcher(patterns)
+func NewIgnorer(patterns []string, legacy bool) (*Ignorer, error) {
+	m, err := NewMatcher(patterns, legacy)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Ignorer{pm: pm}, nil
+	return &Ignorer{ms: []Matcher{m}}, nil
 }
 
 func (i *Ignorer) Ignore(s string) bool {
-	return i.pm.Match(s)
+	for _, m := range i.ms {
+		if m.Match(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// with returns a copy of the ignorer that also ignores the matcher matches
+func (i *Ignorer) with(m Matcher) *Ignorer {
+	ms := append([]Matcher{}, i.ms...)
+	return &Ignorer{ms: append(ms, m)}
 }
@@ -0,0 +1,81 @@
+package specification
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"strings"
+
+	"copy-basta/internal/crawl"
+)
+
+const (
+	gitignoreFile   = ".gitignore"
+	bastaignoreFile = ".bastaignore"
+)
+
+// defaultIgnored are ignored along with the ignore files patterns:
+// the git internals and the .bastaignore files, that are for template development only
+var defaultIgnored = []string{".git/", bastaignoreFile}
+
+// HonorIgnoreFiles ignores, on top of the specification ignored patterns, the patterns of the
+// .gitignore and .bastaignore files of the template, nested ones included, unless the specification opts out.
+// the ignore files are read from the crawled files, their readers are replaced so they can still be loaded
+func (s *Spec) HonorIgnoreFiles(files []crawl.File) error {
+	if !s.honorIgnoreFiles {
+		return nil
+	}
+
+	patterns := append([]string{}, defaultIgnored...)
+	for i, f := range files {
+		name := path.Base(f.Path)
+		if name != gitignoreFile && name != bastaignoreFile {
+			continue
+		}
+
+		content, err := ioutil.ReadAll(f.Reader)
+		if err != nil {
+			return fmt.Errorf("ignore file error [%s]: %s", f.Path, err.Error())
+		}
+		files[i].Reader = bytes.NewReader(content)
+
+		dir := path.Dir(f.Path)
+		scanner := bufio.NewScanner(bytes.NewReader(content))
+		for scanner.Scan() {
+			pattern := strings.TrimSuffix(scanner.Text(), "\r")
+			if strings.TrimSpace(pattern) == "" || strings.HasPrefix(pattern, "#") {
+				continue
+			}
+			patterns = append(patterns, scopePattern(dir, pattern))
+		}
+	}
+
+	m, err := NewGitignoreMatcher(patterns)
+	if err != nil {
+		return fmt.Errorf("ignore file error: %s", err.Error())
+	}
+	s.Ignorer = s.Ignorer.with(m)
+	return nil
+}
+
+// scopePattern scopes the pattern of a nested ignore file to the file directory
+func scopePattern(dir string, pattern string) string {
+	if dir == "." {
+		return pattern
+	}
+
+	negate := ""
+	if strings.HasPrefix(pattern, "!") {
+		negate = "!"
+		pattern = pattern[1:]
+	}
+
+	anchored := strings.Contains(strings.TrimRight(pattern, "/"), "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if anchored {
+		return negate + dir + "/" + pattern
+	}
+	return negate + dir + "/**/" + pattern
+}
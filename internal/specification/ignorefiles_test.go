@@ -0,0 +1,91 @@
+package specification
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/crawl"
+)
+
+func Test_Spec_HonorIgnoreFiles(t *testing.T) {
+	gitignore := "# build output\nbin/\n*.log\n!keep.log\n"
+	files := []crawl.File{
+		{Path: ".gitignore", Reader: strings.NewReader(gitignore)},
+		{Path: ".bastaignore", Reader: strings.NewReader("/notes.md\n")},
+		{Path: "web/.gitignore", Reader: strings.NewReader("node_modules/\n/dist\n")},
+		{Path: "main.go", Reader: strings.NewReader("package main")},
+	}
+
+	tests := []struct {
+		name    string
+		yml     string
+		ignored []string
+		kept    []string
+	}{
+		{
+			name:    "honored",
+			yml:     "version: 3\nignore:\n  - basta.yaml\n",
+			ignored: []string{"basta.yaml", ".git/HEAD", ".bastaignore", "bin/app", "a/b.log", "notes.md", "web/node_modules/x/y.js", "web/a/node_modules/z.js", "web/dist/app.js"},
+			kept:    []string{".gitignore", "web/.gitignore", "main.go", "keep.log", "docs/notes.md", "dist/app.js", "node_modules/x.js"},
+		},
+		{
+			name:    "opted out",
+			yml:     "version: 3\nhonor-ignore-files: false\nignore:\n  - basta.yaml\n",
+			ignored: []string{"basta.yaml"},
+			kept:    []string{".git/HEAD", ".bastaignore", "bin/app", "a/b.log", "notes.md"},
+		},
+		{
+			name:    "legacy spec",
+			yml:     "version: 2\nignore:\n  - basta.yaml\n",
+			ignored: []string{"basta.yaml"},
+			kept:    []string{".git/HEAD", ".bastaignore", "bin/app", "a/b.log", "notes.md"},
+		},
+		{
+			name:    "legacy spec opted in",
+			yml:     "version: 2\nhonor-ignore-files: true\nignore:\n  - basta.yaml\n",
+			ignored: []string{"basta.yaml", ".git/HEAD", ".bastaignore", "bin/app", "a/b.log", "notes.md"},
+			kept:    []string{".gitignore", "main.go", "keep.log"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			require.Nil(t, spec.HonorIgnoreFiles(files))
+			for _, p := range tt.ignored {
+				require.True(t, spec.Ignorer.Ignore(p), p)
+			}
+			for _, p := range tt.kept {
+				require.False(t, spec.Ignorer.Ignore(p), p)
+			}
+		})
+	}
+
+	// the ignore files can still be loaded
+	content, err := ioutil.ReadAll(files[0].Reader)
+	require.Nil(t, err)
+	require.Equal(t, gitignore, string(content))
+}
+
+func Test_scopePattern(t *testing.T) {
+	tests := []struct {
+		dir      string
+		pattern  string
+		expected string
+	}{
+		{dir: ".", pattern: "*.log", expected: "*.log"},
+		{dir: "web", pattern: "*.log", expected: "web/**/*.log"},
+		{dir: "web", pattern: "dist/", expected: "web/**/dist/"},
+		{dir: "web", pattern: "/dist", expected: "web/dist"},
+		{dir: "web", pattern: "src/gen", expected: "web/src/gen"},
+		{dir: "web", pattern: "!keep.log", expected: "!web/**/keep.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			require.Equal(t, tt.expected, scopePattern(tt.dir, tt.pattern))
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package specification
+
+import (
+	"fmt"
+
+	"copy-basta/internal/common"
+)
+
+// a message is the message expression of each translation
+type message struct {
+	text        Text
+	expressions map[string]*expression
+}
+
+func newMessage(raw *Text, declared map[string]struct{}) (*message, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	m := message{text: *raw, expressions: map[string]*expression{}}
+	for lang, translation := range *raw {
+		e, err := newExpression("message", translation)
+		if err != nil {
+			return nil, fmt.Errorf("message error: %s", err.Error())
+		}
+		for _, field := range e.fields() {
+			if _, ok := declared[field]; !ok {
+				return nil, fmt.Errorf("message error: references undeclared variable `%s`", field)
+			}
+		}
+		m.expressions[lang] = e
+	}
+	return &m, nil
+}
+
+// Message renders the message shown after the project is generated, if the template has one
+func (s *Spec) Message(input common.InputVariables) (string, error) {
+	if s.message == nil {
+		return "", nil
+	}
+	rendered, err := s.message.expressions[s.message.text.key(s.lang)].render(s.TemplateInput(input))
+	if err != nil {
+		return "", fmt.Errorf("message error: %s", err.Error())
+	}
+	return rendered, nil
+}
@@ -0,0 +1,54 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_Spec_Message(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		expected string
+	}{
+		{
+			name:     "no message",
+			yml:      "version: 3\n",
+			expected: "",
+		},
+		{
+			name: "templated message",
+			yml: `---
+version: 3
+metadata:
+  name: go-service
+variables:
+  - name: name
+    type: string
+message: |
+  {{ .metadata.name }} generated!
+  cd {{ .name }} && make run
+`,
+			expected: "go-service generated!\ncd api && make run\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			message, err := spec.Message(common.InputVariables{"name": "api"})
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, message)
+		})
+	}
+}
+
+func Test_Spec_Message_error(t *testing.T) {
+	_, err := newFromReader(strings.NewReader("version: 3\nmessage: \"cd {{ .name }}\"\n"), false)
+	require.NotNil(t, err)
+	require.Equal(t, "message error: references undeclared variable `name`", err.Error())
+}
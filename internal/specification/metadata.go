@@ -0,0 +1,89 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/common/semver"
+)
+
+// metadataNamespace is the input namespace the templates access the metadata with (`{{ .metadata.name }}`)
+const metadataNamespace = "metadata"
+
+// Metadata describes the template
+type Metadata struct {
+	Name        string   `yaml:"name"`
+	Version     string   `yaml:"version"`
+	Description string   `yaml:"description"`
+	Authors     []string `yaml:"authors"`
+	Tags        []string `yaml:"tags"`
+	Homepage    string   `yaml:"homepage"`
+}
+
+func (m *Metadata) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("metadata error [name]: is required")
+	}
+	if m.Version != "" {
+		if _, err := semver.Parse(m.Version); err != nil {
+			return fmt.Errorf("metadata error [version]: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+// values returns the metadata as the templates access it
+func (m *Metadata) values() common.InputVariables {
+	return common.InputVariables{
+		"name":        m.Name,
+		"version":     m.Version,
+		"description": m.Description,
+		"authors":     m.Authors,
+		"tags":        m.Tags,
+		"homepage":    m.Homepage,
+	}
+}
+
+func (m *Metadata) String() string {
+	b := strings.Builder{}
+	b.WriteString(m.Name)
+	if m.Version != "" {
+		b.WriteString(" " + m.Version)
+	}
+	b.WriteString("\n")
+	if m.Description != "" {
+		b.WriteString(m.Description + "\n")
+	}
+	if len(m.Authors) > 0 {
+		b.WriteString("authors: " + strings.Join(m.Authors, ", ") + "\n")
+	}
+	if len(m.Tags) > 0 {
+		b.WriteString("tags: " + strings.Join(m.Tags, ", ") + "\n")
+	}
+	if m.Homepage != "" {
+		b.WriteString("homepage: " + m.Homepage + "\n")
+	}
+	return b.String()
+}
+
+// TemplateInput returns the input the template files are rendered with:
+// the input variables and, when the template has them, the metadata and data namespaces.
+// the input of each component gets the component namespaces
+func (s *Spec) TemplateInput(input common.InputVariables) common.InputVariables {
+	templateInput := common.InputVariables{}
+	for k, v := range input {
+		templateInput[k] = v
+	}
+	if s.Metadata != nil {
+		templateInput[metadataNamespace] = s.Metadata.values()
+	}
+	if s.data != nil {
+		templateInput[dataNamespace] = s.data
+	}
+	for _, c := range s.Components {
+		componentInput, _ := input[c.Name].(common.InputVariables)
+		templateInput[c.Name] = c.Spec.TemplateInput(componentInput)
+	}
+	return templateInput
+}
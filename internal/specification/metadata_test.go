@@ -0,0 +1,76 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+const metadataYAML = `---
+version: 3
+metadata:
+  name: go-service
+  version: 1.2.0
+  description: A go http service
+  authors:
+    - vasco
+  tags:
+    - go
+    - HTTP
+  homepage: https://github.com/vasco/go-service
+variables:
+  - name: name
+    type: string
+`
+
+func Test_Spec_Metadata(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(metadataYAML), false)
+	require.Nil(t, err)
+
+	require.Equal(t, "go-service", spec.Metadata.Name)
+	require.Equal(t,
+		"go-service 1.2.0\nA go http service\nauthors: vasco\ntags: go, HTTP\nhomepage: https://github.com/vasco/go-service\n",
+		spec.Metadata.String(),
+	)
+
+	input := common.InputVariables{"name": "api"}
+	templateInput := spec.TemplateInput(input)
+	require.Equal(t, "api", templateInput["name"])
+	require.Equal(t, "1.2.0", templateInput["metadata"].(common.InputVariables)["version"])
+	_, ok := input["metadata"]
+	require.False(t, ok)
+}
+
+func Test_Spec_Metadata_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "missing name",
+			yml:  "version: 3\nmetadata:\n  version: 1.0.0\n",
+			err:  "metadata error [name]: is required",
+		},
+		{
+			name: "invalid version",
+			yml:  "version: 3\nmetadata:\n  name: t\n  version: one\n",
+			err:  "metadata error [version]",
+		},
+		{
+			name: "reserved name",
+			yml:  "version: 3\nmetadata:\n  name: t\nvariables:\n  - name: metadata\n",
+			err:  "`metadata` is reserved",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package specification
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"copy-basta/internal/common"
+)
+
+// validateName checks that each segment of the dotted variable name can be used as a template
+// field (`{{ .db.host }}`), suggesting a fix when it can't
+func validateName(name string) error {
+	for _, segment := range strings.Split(name, ".") {
+		if !isTemplateIdentifier(segment) {
+			return fmt.Errorf("variable error [name]: `%s` is not a valid template identifier, try `%s`", name, suggestName(name))
+		}
+	}
+	return nil
+}
+
+func isTemplateIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if !(unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r))) {
+			return false
+		}
+	}
+	return true
+}
+
+// suggestName camel cases the segments of the name around the characters identifiers can't have
+// (`db-host` to `dbHost`), dropping the empty ones
+func suggestName(name string) string {
+	var segments []string
+	for _, segment := range strings.Split(name, ".") {
+		words := strings.FieldsFunc(segment, func(r rune) bool {
+			return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+		})
+		for i := 1; i < len(words); i++ {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+		s := strings.Join(words, "")
+		if s == "" {
+			continue
+		}
+		if unicode.IsDigit(rune(s[0])) {
+			s = "_" + s
+		}
+		segments = append(segments, s)
+	}
+	return strings.Join(segments, ".")
+}
+
+// root returns the input key holding the variable value, the first segment of its name
+func (v *Variable) root() string {
+	if v.legacyName {
+		return v.name
+	}
+	return strings.Split(v.name, ".")[0]
+}
+
+// lookup returns the variable value from the input
+func (v *Variable) lookup(input common.InputVariables) (interface{}, bool) {
+	if v.legacyName {
+		value, ok := input[v.name]
+		return value, ok
+	}
+	return lookupValue(input, v.name)
+}
+
+// set sets the variable value in the input, nested by the segments of its name
+func (v *Variable) set(input common.InputVariables, value interface{}) error {
+	if v.legacyName {
+		input[v.name] = value
+		return nil
+	}
+	return setValue(input, v.name, value)
+}
+
+// refersTo tells whether the field path, as in `db.host`, refers to the variable. the path can
+// reach into the variable value (`db.host.port`) or hold it (`db`)
+func (v *Variable) refersTo(path string) bool {
+	if v.legacyName {
+		return path == v.name || strings.HasPrefix(path, v.name+".")
+	}
+	return path == v.name || strings.HasPrefix(path, v.name+".") || strings.HasPrefix(v.name, path+".")
+}
+
+// validateNames checks that no variable value is nested under another variable (`db` and `db.host`)
+func (vars Variables) validateNames() error {
+	for i := range vars {
+		for j := range vars {
+			if i != j && strings.HasPrefix(vars[j].name, vars[i].name+".") {
+				return fmt.Errorf("variable error [name]: `%s` is nested under variable `%s`", vars[j].name, vars[i].name)
+			}
+		}
+	}
+	return nil
+}
+
+// flatten adds the values to flat, keyed by variable name. the values of dotted names can be
+// nested (`db: {host: localhost}`) or keyed by the name itself (`db.host: localhost`)
+func (vars Variables) flatten(values common.InputVariables, prefix string, flat common.InputVariables) error {
+	for key, value := range values {
+		name := qualify(prefix, key)
+		if vars.get(name) != nil {
+			flat[name] = value
+			continue
+		}
+		nested, err := toInputVariables(value)
+		if err != nil || value == nil || !vars.nest(name) {
+			return fmt.Errorf("unknown variable `%s`", name)
+		}
+		if err := vars.flatten(nested, name, flat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// nest tells whether variables are nested under the name
+func (vars Variables) nest(name string) bool {
+	for i := range vars {
+		if !vars[i].legacyName && strings.HasPrefix(vars[i].name, name+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// lookupValue returns the value of the dotted name from the input, found either
+// under the name itself or in the nested maps of its segments
+func lookupValue(input common.InputVariables, name string) (interface{}, bool) {
+	if value, ok := input[name]; ok {
+		return value, true
+	}
+	segments := strings.Split(name, ".")
+	var current interface{} = input
+	for _, segment := range segments {
+		m, err := toInputVariables(current)
+		if err != nil || current == nil {
+			return nil, false
+		}
+		value, ok := m[segment]
+		if !ok {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+// setValue sets the value of the dotted name in the input, nesting it in a map by segment.
+// a value provided under the dotted name itself is replaced by the nested one
+func setValue(input common.InputVariables, name string, value interface{}) error {
+	segments := strings.Split(name, ".")
+	if len(segments) > 1 {
+		delete(input, name)
+	}
+	current := input
+	for _, segment := range segments[:len(segments)-1] {
+		nested, err := toInputVariables(current[segment])
+		if err != nil {
+			return fmt.Errorf("variable error [%s]: %s", name, err.Error())
+		}
+		current[segment] = nested
+		current = nested
+	}
+	current[segments[len(segments)-1]] = value
+	return nil
+}
@@ -0,0 +1,168 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_validateName(t *testing.T) {
+	tests := []struct {
+		name     string
+		in       string
+		expected string
+	}{
+		{name: "simple", in: "dbHost"},
+		{name: "dotted", in: "db.host"},
+		{name: "underscore", in: "_db.host_1"},
+		{name: "dash", in: "db-host", expected: "try `dbHost`"},
+		{name: "dashed segment", in: "db.host-name", expected: "try `db.hostName`"},
+		{name: "empty segment", in: "db..host", expected: "try `db.host`"},
+		{name: "leading digit", in: "3scale", expected: "try `_3scale`"},
+		{name: "space", in: "db host", expected: "try `dbHost`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateName(tt.in)
+			if tt.expected == "" {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+const dottedSpec = `---
+version: 3
+variables:
+  - name: db.host
+    type: string
+  - name: db.port
+    type: integer
+    default: 5432
+  - name: dsn
+    type: string
+    value: "{{ .db.host }}:{{ .db.port }}"
+  - name: db.name
+    type: string
+    default: "{{ .app.name }}"
+  - name: app.name
+    type: string
+`
+
+func Test_Spec_dottedNames(t *testing.T) {
+	expected := common.InputVariables{
+		"app": common.InputVariables{"name": "api"},
+		"db":  common.InputVariables{"host": "localhost", "port": 5432, "name": "api"},
+		"dsn": "localhost:5432",
+	}
+
+	spec, err := newFromReader(strings.NewReader(dottedSpec), false)
+	require.Nil(t, err)
+	var names []string
+	for _, v := range spec.Variables {
+		names = append(names, v.name)
+	}
+	require.Equal(t, []string{"db.host", "db.port", "dsn", "app.name", "db.name"}, names)
+
+	input, err := spec.fromReader(newLineReader(strings.NewReader("localhost\n\napi\n\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = spec.fromInput(common.InputVariables{
+		"db":       map[interface{}]interface{}{"host": "localhost"},
+		"app.name": "api",
+	})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
+
+func Test_Spec_dottedNames_error(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		expected string
+	}{
+		{
+			name: "invalid identifier",
+			yml: `---
+version: 3
+variables:
+  - name: db-host
+`,
+			expected: "variable error [name]: `db-host` is not a valid template identifier, try `dbHost`",
+		},
+		{
+			name: "nested under variable",
+			yml: `---
+version: 3
+variables:
+  - name: db
+    type: object
+  - name: db.host
+`,
+			expected: "variable error [name]: `db.host` is nested under variable `db`",
+		},
+		{
+			name: "undeclared path",
+			yml: `---
+version: 3
+variables:
+  - name: db.host
+  - name: dsn
+    value: "{{ .db.port }}"
+`,
+			expected: "variable error [dsn]: references undeclared variable `db.port`",
+		},
+		{
+			name: "component name",
+			yml: `---
+version: 3
+variables:
+  - name: db.host
+components:
+  - name: db
+    src: ../postgres
+`,
+			expected: "component error [name]: `db` is already used",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			if err == nil {
+				err = spec.LoadComponents("/tmp", false)
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func Test_Spec_legacyNames(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(`---
+version: 2
+variables:
+  - name: project-name
+    type: string
+  - name: db.host
+    type: string
+  - name: db
+    type: string
+`), false)
+	require.Nil(t, err)
+
+	expected := common.InputVariables{"project-name": "api", "db.host": "localhost", "db": "postgres"}
+	input, err := spec.fromInput(common.InputVariables{"project-name": "api", "db.host": "localhost", "db": "postgres"})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = spec.fromReader(newLineReader(strings.NewReader("api\nlocalhost\npostgres\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
@@ -1,18 +1,18 @@
 package specification
 
 type Passer struct {
-	pm *PatternMatcher
+	m Matcher
 }
 
-func NewPasser(passThrough []string) (*Passer, error) {
-	pm, err := NewPatternMatcher(passThrough)
+func NewPasser(passThrough []string, legacy bool) (*Passer, error) {
+	m, err := NewMatcher(passThrough, legacy)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Passer{pm: pm}, nil
+	return &Passer{m: m}, nil
 }
 
 func (i *Passer) Pass(s string) bool {
-	return i.pm.Match(s)
+	return i.m.Match(s)
 }
@@ -0,0 +1,161 @@
+package specification
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// path types are not open-api types: the user enters a path, and the templates
+// get the file contents or the directory listing
+const (
+	pathFile      = "file"
+	pathDirectory = "directory"
+)
+
+var pathTypes = []string{
+	pathFile,
+	pathDirectory,
+}
+
+const (
+	encodingText   = "text"
+	encodingBase64 = "base64"
+)
+
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GB", 1 << 30},
+	{"MB", 1 << 20},
+	{"KB", 1 << 10},
+	{"B", 1},
+}
+
+// parseSize parses sizes as `512`, `64KB` or `1MB`
+func parseSize(s string) (int64, error) {
+	number, unit := strings.ToUpper(strings.TrimSpace(s)), int64(1)
+	for _, u := range sizeUnits {
+		if strings.HasSuffix(number, u.suffix) {
+			number, unit = strings.TrimSpace(strings.TrimSuffix(number, u.suffix)), u.bytes
+			break
+		}
+	}
+	n, err := strconv.ParseInt(number, 10, 64)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("`%s` is not a valid size, expected a positive number of B, KB, MB or GB", s)
+	}
+	return n * unit, nil
+}
+
+func (v *Variable) isPath() bool {
+	return v.dtype != nil && (*v.dtype == pathFile || *v.dtype == pathDirectory)
+}
+
+// a boundValue is the value of a file or directory component variable bound by the parent template.
+// it holds contents already, so it is not read as a path again
+type boundValue struct {
+	value interface{}
+}
+
+func (v *Variable) validatePath() error {
+	if v.isPath() && v.value != nil {
+		return errors.New("variable error [value]: file and directory variables can't be computed, their path is entered by the user")
+	}
+	if v.isPath() && (v.defaultVal != nil || v.defaultTmpl != nil) {
+		return errors.New("variable error [default]: file and directory variables can't have a default, their path is entered by the user")
+	}
+	if v.maxSize != nil && (v.dtype == nil || *v.dtype != pathFile) {
+		return errors.New("variable error [max-size]: only file variables can have a max-size")
+	}
+	if v.encoding == nil {
+		return nil
+	}
+	if v.dtype == nil || *v.dtype != pathFile {
+		return errors.New("variable error [encoding]: only file variables can have an encoding")
+	}
+	if *v.encoding != encodingText && *v.encoding != encodingBase64 {
+		return fmt.Errorf("variable error [encoding]: %s is not a valid encoding, expected %s or %s", *v.encoding, encodingText, encodingBase64)
+	}
+	return nil
+}
+
+// pathOk checks that the path entered for a file or directory variable exists,
+// and that files are within the size limit
+func (v *Variable) pathOk(value interface{}) error {
+	path, ok := value.(string)
+	if !v.isPath() || !ok || path == "" {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("value error: %s does not exist", path)
+	}
+	switch {
+	case *v.dtype == pathFile && info.IsDir():
+		return fmt.Errorf("value error: %s is a directory, expected a file", path)
+	case *v.dtype == pathDirectory && !info.IsDir():
+		return fmt.Errorf("value error: %s is not a directory", path)
+	case v.maxSize != nil && info.Size() > *v.maxSize:
+		return fmt.Errorf("value error: %s is %d bytes, larger than the %d bytes limit", path, info.Size(), *v.maxSize)
+	}
+	return nil
+}
+
+// pathValue returns the contents of the file, or the sorted paths of the directory files.
+// it is called once, when the path entered by the user is accepted
+func (v *Variable) pathValue(path string) (interface{}, error) {
+	if err := v.pathOk(path); err != nil {
+		return nil, fmt.Errorf("variable error [%s]: %s", v.qualifiedName(), err.Error())
+	}
+	if *v.dtype == pathDirectory {
+		return listDirectory(path)
+	}
+	if path == "" {
+		return "", nil
+	}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("variable error [%s]: %s", v.qualifiedName(), err.Error())
+	}
+	if v.encoding != nil && *v.encoding == encodingBase64 {
+		return base64.StdEncoding.EncodeToString(content), nil
+	}
+	if !utf8.Valid(content) {
+		return nil, fmt.Errorf("variable error [%s]: %s is not a text file, use the %s encoding", v.qualifiedName(), path, encodingBase64)
+	}
+	return string(content), nil
+}
+
+func listDirectory(dir string) ([]string, error) {
+	files := []string{}
+	if dir == "" {
+		return files, nil
+	}
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
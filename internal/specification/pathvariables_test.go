@@ -0,0 +1,268 @@
+package specification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_parseSize(t *testing.T) {
+	tests := []struct {
+		in       string
+		expected int64
+		err      bool
+	}{
+		{in: "512", expected: 512},
+		{in: "512B", expected: 512},
+		{in: "64KB", expected: 64 << 10},
+		{in: "1 mb", expected: 1 << 20},
+		{in: "2GB", expected: 2 << 30},
+		{in: "0", err: true},
+		{in: "a lot", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			size, err := parseSize(tt.in)
+			if tt.err {
+				require.NotNil(t, err)
+				return
+			}
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, size)
+		})
+	}
+}
+
+func Test_Spec_pathVariables(t *testing.T) {
+	root, err := ioutil.TempDir("", "paths")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestTemplate(t, root, map[string]string{
+		"schema.sql":                "create table users;",
+		"migrations/001_users.sql":  "create table users;",
+		"migrations/002_orders.sql": "create table orders;",
+		"migrations/down/001.sql":   "drop table users;",
+	})
+
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: schema
+    type: file
+    max-size: 1KB
+  - name: encoded
+    type: file
+    encoding: base64
+  - name: migrations
+    type: directory
+  - name: seeds
+    type: file
+    required: false
+`), false)
+	require.Nil(t, err)
+
+	schema := filepath.Join(root, "schema.sql")
+	migrations := filepath.Join(root, "migrations")
+	expected := common.InputVariables{
+		"schema":     "create table users;",
+		"encoded":    "Y3JlYXRlIHRhYmxlIHVzZXJzOw==",
+		"migrations": []string{"001_users.sql", "002_orders.sql", "down/001.sql"},
+		"seeds":      "",
+	}
+
+	input, err := spec.fromInput(common.InputVariables{"schema": schema, "encoded": schema, "migrations": migrations})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	stdin := strings.Join([]string{filepath.Join(root, "missing.sql"), schema, schema, schema, migrations, ""}, "\n")
+	input, err = spec.fromReader(newLineReader(strings.NewReader(stdin+"\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
+
+func Test_Spec_pathVariables_error(t *testing.T) {
+	root, err := ioutil.TempDir("", "paths")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestTemplate(t, root, map[string]string{"schema.sql": strings.Repeat("-", 2048)})
+	schema := filepath.Join(root, "schema.sql")
+
+	tests := []struct {
+		name     string
+		yml      string
+		input    common.InputVariables
+		expected string
+	}{
+		{
+			name:     "encoding of a directory",
+			yml:      "version: 3\nvariables:\n  - name: dir\n    type: directory\n    encoding: base64\n",
+			expected: "variable error [encoding]: only file variables can have an encoding",
+		},
+		{
+			name:     "unknown encoding",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n    encoding: hex\n",
+			expected: "variable error [encoding]: hex is not a valid encoding, expected text or base64",
+		},
+		{
+			name:     "max size of a string",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: string\n    max-size: 1KB\n",
+			expected: "variable error [max-size]: only file variables can have a max-size",
+		},
+		{
+			name:     "computed file",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n    value: /etc/passwd\n",
+			expected: "variable error [value]: file and directory variables can't be computed",
+		},
+		{
+			name:     "file default",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n    default: /etc/passwd\n",
+			expected: "variable error [default]: file and directory variables can't have a default",
+		},
+		{
+			name:     "missing file",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n",
+			input:    common.InputVariables{"schema": filepath.Join(root, "missing.sql")},
+			expected: "missing.sql does not exist",
+		},
+		{
+			name:     "file is a directory",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n",
+			input:    common.InputVariables{"schema": root},
+			expected: "is a directory, expected a file",
+		},
+		{
+			name:     "too large",
+			yml:      "version: 3\nvariables:\n  - name: schema\n    type: file\n    max-size: 1KB\n",
+			input:    common.InputVariables{"schema": schema},
+			expected: "schema.sql is 2048 bytes, larger than the 1024 bytes limit",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			if err == nil {
+				_, err = spec.fromInput(tt.input)
+			}
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func Test_Spec_pathVariables_bound(t *testing.T) {
+	root, err := ioutil.TempDir("", "paths")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestTemplate(t, root, map[string]string{
+		"schema.sql": "create table users;",
+		"postgres/basta.yaml": `---
+version: 3
+variables:
+  - name: schema
+    type: file
+`,
+	})
+	schema := filepath.Join(root, "schema.sql")
+
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: schema
+    type: file
+components:
+  - name: db
+    src: ../postgres
+    bind:
+      schema: "{{ .schema }}"
+`), false)
+	require.Nil(t, err)
+	require.Nil(t, spec.LoadComponents(filepath.Join(root, "service"), false))
+
+	expected := common.InputVariables{
+		"schema": "create table users;",
+		"db":     common.InputVariables{"schema": "create table users;"},
+	}
+	input, err := spec.fromInput(common.InputVariables{"schema": schema})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = spec.fromReader(newLineReader(strings.NewReader(schema+"\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
+
+func Test_Spec_pathVariables_boundDirectory(t *testing.T) {
+	root, err := ioutil.TempDir("", "paths")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestTemplate(t, root, map[string]string{
+		"migrations/a.sql": "create table a;",
+		"migrations/b.sql": "create table b;",
+		"postgres/basta.yaml": `---
+version: 3
+variables:
+  - name: migrations
+    type: directory
+`,
+	})
+	migrations := filepath.Join(root, "migrations")
+
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: migrations
+    type: directory
+components:
+  - name: db
+    src: ../postgres
+    bind:
+      migrations: "{{ .migrations }}"
+`), false)
+	require.Nil(t, err)
+	require.Nil(t, spec.LoadComponents(filepath.Join(root, "service"), false))
+
+	input, err := spec.fromInput(common.InputVariables{"migrations": migrations})
+	require.Nil(t, err)
+	expected := common.InputVariables{
+		"migrations": []string{"a.sql", "b.sql"},
+		"db":         common.InputVariables{"migrations": []string{"a.sql", "b.sql"}},
+	}
+	require.Equal(t, expected, input)
+
+	out := strings.Builder{}
+	tmpl, err := common.NewTemplate("migrations").Parse("{{ range .migrations }}{{ . }} {{ end }}")
+	require.Nil(t, err)
+	require.Nil(t, tmpl.Execute(&out, input["db"]))
+	require.Equal(t, "a.sql b.sql ", out.String())
+}
+
+func Test_Spec_pathVariables_boundDirectory_error(t *testing.T) {
+	root, err := ioutil.TempDir("", "paths")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	writeTestTemplate(t, root, map[string]string{
+		"postgres/basta.yaml": "version: 3\nvariables:\n  - name: migrations\n    type: directory\n",
+	})
+
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: name
+    type: string
+components:
+  - name: db
+    src: ../postgres
+    bind:
+      migrations: "{{ .name }}/migrations"
+`), false)
+	require.Nil(t, err)
+	err = spec.LoadComponents(filepath.Join(root, "service"), false)
+	require.NotNil(t, err)
+	require.Equal(t, "component error [db]: bind error [migrations]: a directory variable can only be bound to a directory variable (`{{ .name }}`)", err.Error())
+}
@@ -0,0 +1,63 @@
+package specification
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"copy-basta/internal/common"
+)
+
+// newPresets flattens the presets values and checks they set declared, not computed, variables.
+// the values are checked by validatePresets
+func newPresets(presetsData map[string]map[string]interface{}, variables Variables) (map[string]common.InputVariables, error) {
+	presets := map[string]common.InputVariables{}
+	for name, values := range presetsData {
+		flat := common.InputVariables{}
+		if err := variables.flatten(values, "", flat); err != nil {
+			return nil, fmt.Errorf("preset error [%s]: %s", name, err.Error())
+		}
+		for varName := range flat {
+			if variables.get(varName).value != nil {
+				return nil, fmt.Errorf("preset error [%s]: `%s` is computed, it can't be preset", name, varName)
+			}
+		}
+		presets[name] = flat
+	}
+	return presets, nil
+}
+
+// validatePresets checks the presets values against the variables, as their defaults are.
+// it runs once the enum choices are resolved from the data files
+func (s *Spec) validatePresets() error {
+	for name, preset := range s.presets {
+		for varName, value := range preset {
+			if err := s.Variables.get(varName).valueOk(value); err != nil {
+				return fmt.Errorf("preset error [%s]: variable `%s`: %s", name, varName, err.Error())
+			}
+		}
+	}
+	return nil
+}
+
+// preset returns a copy of the preset values, none when the name is empty
+func (s *Spec) preset(name string) (common.InputVariables, error) {
+	values := common.InputVariables{}
+	if name == "" {
+		return values, nil
+	}
+
+	preset, ok := s.presets[name]
+	if !ok {
+		var names []string
+		for n := range s.presets {
+			names = append(names, n)
+		}
+		sort.Strings(names)
+		return nil, fmt.Errorf("preset error: unknown preset `%s`. available presets are [%s]", name, strings.Join(names, ", "))
+	}
+	for k, v := range preset {
+		values[k] = v
+	}
+	return values, nil
+}
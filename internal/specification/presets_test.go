@@ -0,0 +1,139 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+const presetsYAML = `---
+version: 3
+variables:
+  - name: name
+    type: string
+  - name: database
+    type: boolean
+    default: false
+  - name: replicas
+    type: integer
+    default: 1
+presets:
+  minimal:
+    database: false
+  full:
+    database: true
+    replicas: 3
+`
+
+func Test_Spec_presets(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(presetsYAML), false)
+	require.Nil(t, err)
+
+	values, err := spec.preset("full")
+	require.Nil(t, err)
+	input, err := spec.fromReader(newLineReader(strings.NewReader("api\n")), values)
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"name": "api", "database": true, "replicas": 3}, input)
+
+	values, err = spec.preset("minimal")
+	require.Nil(t, err)
+	values["name"] = "api"
+	values["replicas"] = 2
+	input, err = spec.fromInput(values)
+	require.Nil(t, err)
+	require.Equal(t, false, input["database"])
+	require.Equal(t, 2, input["replicas"])
+
+	// the spec presets are not altered
+	values, err = spec.preset("minimal")
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"database": false}, values)
+
+	_, err = spec.preset("lambda")
+	require.NotNil(t, err)
+	require.Equal(t, "preset error: unknown preset `lambda`. available presets are [full, minimal]", err.Error())
+}
+
+func Test_newPresets_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "unknown variable",
+			yml:  "version: 3\npresets:\n  full:\n    database: true\n",
+			err:  "preset error [full]: unknown variable `database`",
+		},
+		{
+			name: "wrong type",
+			yml:  "version: 3\nvariables:\n  - name: replicas\n    type: integer\npresets:\n  full:\n    replicas: three\n",
+			err:  "preset error [full]: variable `replicas`: value error",
+		},
+		{
+			name: "computed variable",
+			yml:  "version: 3\nvariables:\n  - name: a\n    type: string\n    value: x\npresets:\n  full:\n    a: y\n",
+			err:  "preset error [full]: `a` is computed, it can't be preset",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func Test_Spec_presets_dottedNames(t *testing.T) {
+	_, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: db.host
+    type: string
+  - name: db.port
+    type: integer
+presets:
+  nested:
+    db:
+      host: localhost
+      port: 5432
+  flat:
+    db.host: localhost
+    db.port: 5432
+  unknown:
+    db:
+      hots: localhost
+`), false)
+	require.NotNil(t, err)
+	require.Equal(t, "preset error [unknown]: unknown variable `db.hots`", err.Error())
+
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+variables:
+  - name: db.host
+    type: string
+  - name: db.port
+    type: integer
+presets:
+  nested:
+    db:
+      host: localhost
+      port: 5432
+  flat:
+    db.host: localhost
+    db.port: 5432
+`), false)
+	require.Nil(t, err)
+	expected := common.InputVariables{"db": common.InputVariables{"host": "localhost", "port": 5432}}
+	for _, preset := range []string{"nested", "flat"} {
+		values, err := spec.preset(preset)
+		require.Nil(t, err)
+		input, err := spec.fromReader(newLineReader(strings.NewReader("")), values)
+		require.Nil(t, err)
+		require.Equal(t, expected, input)
+	}
+}
@@ -0,0 +1,38 @@
+package specification
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// A lineReader reads the user input line by line
+type lineReader struct {
+	r *bufio.Reader
+	// fd is set when reading from a terminal, so that secrets can be read without echo
+	fd *int
+}
+
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
+}
+
+func newStdinReader() *lineReader {
+	lr := newLineReader(os.Stdin)
+	if fd := int(os.Stdin.Fd()); terminal.IsTerminal(fd) {
+		lr.fd = &fd
+	}
+	return lr
+}
+
+func (lr *lineReader) readLine(secret bool) (string, error) {
+	if secret && lr.fd != nil {
+		line, err := terminal.ReadPassword(*lr.fd)
+		return string(line), err
+	}
+	line, err := lr.r.ReadString('\n')
+	return strings.TrimSuffix(line, "\n"), err
+}
@@ -0,0 +1,131 @@
+package specification
+
+import (
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"copy-basta/internal/common/semver"
+)
+
+// defaultVersionRegexp finds the first version in a command output
+var defaultVersionRegexp = regexp.MustCompile(`(\d+\.\d+(?:\.\d+)?)`)
+
+// versionArguments are the arguments a requirement command can run its executable with,
+// so templates can read versions but can't run arbitrary commands
+var versionArguments = map[string]struct{}{
+	"--version": {},
+	"version":   {},
+	"-version":  {},
+	"-v":        {},
+}
+
+// A requirement is an executable the generated project needs
+type requirement struct {
+	name       string
+	constraint *semver.Constraint
+	command    []string
+	regexp     *regexp.Regexp
+}
+
+func newRequirements(requirementsData []RequirementData) ([]requirement, error) {
+	var requirements []requirement
+	for _, rd := range requirementsData {
+		if rd.Name == "" {
+			return nil, fmt.Errorf("requirement error [name]: is required")
+		}
+		if strings.ContainsAny(rd.Name, `/\`) {
+			return nil, fmt.Errorf("requirement error [%s]: name must be an executable found in PATH, not a path", rd.Name)
+		}
+		r := requirement{name: rd.Name, regexp: defaultVersionRegexp}
+
+		if rd.Version != nil {
+			constraint, err := semver.ParseConstraint(*rd.Version)
+			if err != nil {
+				return nil, fmt.Errorf("requirement error [%s]: %s", rd.Name, err.Error())
+			}
+			r.constraint = constraint
+		}
+
+		r.command = []string{rd.Name, "--version"}
+		if rd.Command != nil {
+			r.command = strings.Fields(*rd.Command)
+			if len(r.command) == 0 {
+				return nil, fmt.Errorf("requirement error [%s]: command can't be empty", rd.Name)
+			}
+			// the command only picks the version argument, so templates can't run other commands than the version ones
+			if len(r.command) != 2 || r.command[0] != rd.Name {
+				return nil, fmt.Errorf("requirement error [%s]: command must be `%s <argument>` (%s)", rd.Name, rd.Name, *rd.Command)
+			}
+			if _, ok := versionArguments[r.command[1]]; !ok {
+				return nil, fmt.Errorf("requirement error [%s]: command argument must be one of --version, version, -version or -v (%s)", rd.Name, *rd.Command)
+			}
+		}
+
+		if rd.Regex != nil {
+			re, err := regexp.Compile(*rd.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("requirement error [%s]: regex error: %s", rd.Name, err.Error())
+			}
+			if re.NumSubexp() > 1 {
+				return nil, fmt.Errorf("requirement error [%s]: regex can have one group at most", rd.Name)
+			}
+			r.regexp = re
+		}
+		requirements = append(requirements, r)
+	}
+	return requirements, nil
+}
+
+// check checks the executable is found and, when constrained, that its version satisfies the constraint.
+// without a constraint, the version command is not run
+func (r *requirement) check() error {
+	if _, err := exec.LookPath(r.name); err != nil {
+		return fmt.Errorf("%s: not found", r.name)
+	}
+	if r.constraint == nil {
+		return nil
+	}
+
+	out, err := exec.Command(r.command[0], r.command[1:]...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s: `%s` failed: %s", r.name, strings.Join(r.command, " "), err.Error())
+	}
+	m := r.regexp.FindStringSubmatch(string(out))
+	if m == nil {
+		return fmt.Errorf("%s: no version found in the `%s` output", r.name, strings.Join(r.command, " "))
+	}
+	found := m[len(m)-1]
+	version, err := semver.ParseTolerant(found)
+	if err != nil {
+		return fmt.Errorf("%s: %s", r.name, err.Error())
+	}
+	if !r.constraint.Check(*version) {
+		return fmt.Errorf("%s: version %s does not satisfy %s", r.name, found, r.constraint)
+	}
+	return nil
+}
+
+// CheckRequirements checks the executables required by the template and its components.
+// all the failures are reported together
+func (s *Spec) CheckRequirements() error {
+	failures := s.requirementsFailures()
+	if len(failures) == 0 {
+		return nil
+	}
+	return fmt.Errorf("requirements error:\n - %s", strings.Join(failures, "\n - "))
+}
+
+func (s *Spec) requirementsFailures() []string {
+	var failures []string
+	for _, r := range s.requirements {
+		if err := r.check(); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	for _, c := range s.Components {
+		failures = append(failures, c.Spec.requirementsFailures()...)
+	}
+	return failures
+}
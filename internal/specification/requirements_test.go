@@ -0,0 +1,140 @@
+package specification
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// withFakeBinaries puts executables printing the given outputs first on PATH
+func withFakeBinaries(t *testing.T, outputs map[string]string) func() {
+	dir, err := ioutil.TempDir("", "bin")
+	require.Nil(t, err)
+	for name, output := range outputs {
+		script := "#!/bin/sh\necho '" + output + "'\n"
+		require.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(script), 0755))
+	}
+
+	path := os.Getenv("PATH")
+	require.Nil(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+path))
+	return func() {
+		_ = os.Setenv("PATH", path)
+		_ = os.RemoveAll(dir)
+	}
+}
+
+func Test_Spec_CheckRequirements(t *testing.T) {
+	defer withFakeBinaries(t, map[string]string{
+		"fakego":     "go version go1.21.3 linux/amd64",
+		"fakeprotoc": "libprotoc 3.12.4",
+		"fakedocker": "Docker version 20.10.7, build f0df350",
+	})()
+
+	tests := []struct {
+		name     string
+		yml      string
+		failures []string
+	}{
+		{
+			name: "met",
+			yml: `---
+version: 3
+requires:
+  - name: fakego
+    version: ">= 1.21"
+    command: fakego version
+    regex: 'go(\d+\.\d+(?:\.\d+)?)'
+  - name: fakeprotoc
+    version: ">= 3, < 4"
+  - name: fakedocker
+`,
+		},
+		{
+			name: "not met",
+			yml: `---
+version: 3
+requires:
+  - name: fakego
+    version: ">= 1.22"
+    command: fakego version
+    regex: 'go(\d+\.\d+(?:\.\d+)?)'
+  - name: fakeprotoc
+    version: "< 3"
+  - name: fakemissing
+  - name: fakedocker
+    version: ">= 1"
+    regex: 'podman (\d+)'
+`,
+			failures: []string{
+				"fakego: version 1.21.3 does not satisfy >= 1.22",
+				"fakeprotoc: version 3.12.4 does not satisfy < 3",
+				"fakemissing: not found",
+				"fakedocker: no version found in the `fakedocker --version` output",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+
+			err = spec.CheckRequirements()
+			if tt.failures == nil {
+				require.Nil(t, err)
+				return
+			}
+			require.NotNil(t, err)
+			require.Equal(t, "requirements error:\n - "+strings.Join(tt.failures, "\n - "), err.Error())
+		})
+	}
+}
+
+func Test_newRequirements_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+		err  string
+	}{
+		{
+			name: "invalid constraint",
+			yml:  "version: 3\nrequires:\n  - name: go\n    version: \"~> 1.2\"\n",
+			err:  "requirement error [go]: semver error",
+		},
+		{
+			name: "invalid regex",
+			yml:  "version: 3\nrequires:\n  - name: go\n    regex: \"go(\"\n",
+			err:  "requirement error [go]: regex error",
+		},
+		{
+			name: "command of another executable",
+			yml:  "version: 3\nrequires:\n  - name: go\n    version: \">= 1\"\n    command: rm -rf /tmp/x\n",
+			err:  "requirement error [go]: command must be `go <argument>` (rm -rf /tmp/x)",
+		},
+		{
+			name: "command with extra arguments",
+			yml:  "version: 3\nrequires:\n  - name: sh\n    version: \">= 1\"\n    command: sh -c touch${IFS}/tmp/x;echo${IFS}1.0.0\n",
+			err:  "requirement error [sh]: command must be `sh <argument>`",
+		},
+		{
+			name: "command with another argument",
+			yml:  "version: 3\nrequires:\n  - name: sh\n    version: \">= 1\"\n    command: sh /tmp/x.sh\n",
+			err:  "requirement error [sh]: command argument must be one of --version, version, -version or -v (sh /tmp/x.sh)",
+		},
+		{
+			name: "path name",
+			yml:  "version: 3\nrequires:\n  - name: /tmp/evil\n",
+			err:  "requirement error [/tmp/evil]: name must be an executable found in PATH, not a path",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
@@ -4,20 +4,33 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 
-	"gopkg.in/yaml.v2"
-
+	"copy-basta/internal/common"
 	"copy-basta/internal/common/log"
 	"copy-basta/internal/crawl"
 )
 
 type Spec struct {
-	Ignorer   *Ignorer
-	Passer    *Passer
-	Variables Variables
+	Metadata   *Metadata
+	Ignorer    *Ignorer
+	Passer     *Passer
+	Ruler      *Ruler
+	Variables  Variables
+	Components []*Component
+
+	componentsData   []ComponentData
+	honorIgnoreFiles bool
+	message          *message
+	requirements     []requirement
+	presets          map[string]common.InputVariables
+	dataFiles        []DataFileData
+	data             common.InputVariables
+	lang             string
 }
 
-func New(specFileName string, files []crawl.File, overwrite bool) (*Spec, error) {
+// Load finds the specification file among the crawled files and decodes it
+func Load(specFileName string, files []crawl.File) (*SpecData, error) {
 	var specFile *crawl.File
 	for _, f := range files {
 		if f.Path == specFileName {
@@ -29,15 +42,28 @@ func New(specFileName string, files []crawl.File, overwrite bool) (*Spec, error)
 		return nil, fmt.Errorf("specification: failed to find spec file (%s)", specFileName)
 	}
 
-	return newFromReader(specFile.Reader, overwrite)
+	return decodeReader(specFile.Reader)
 }
 
-func newFromReader(r io.Reader, overwrite bool) (*Spec, error) {
-	data := SpecData{}
-	err := yaml.NewDecoder(r).Decode(&data)
+func decodeReader(r io.Reader) (*SpecData, error) {
+	raw, err := ioutil.ReadAll(r)
 	if err != nil {
 		log.L.DebugWithData("external error", log.Data{"error": err.Error()})
-		return nil, errors.New("specification yaml file error: failed to decode yaml")
+		return nil, errors.New("specification yaml file error: failed to read file")
+	}
+
+	return decode(raw)
+}
+
+func New(data *SpecData, overwrite bool) (*Spec, error) {
+	if data.Extends != "" {
+		return nil, fmt.Errorf("specification error: extends (%s) was not resolved", data.Extends)
+	}
+
+	if data.Metadata != nil {
+		if err := data.Metadata.validate(); err != nil {
+			return nil, err
+		}
 	}
 
 	var ignoredPatterns []string
@@ -46,24 +72,226 @@ func newFromReader(r io.Reader, overwrite bool) (*Spec, error) {
 		ignoredPatterns = append(ignoredPatterns, data.OnOverwrite.Exclude...)
 	}
 
-	ignorer, err := NewIgnorer(ignoredPatterns)
+	ignorer, err := NewIgnorer(ignoredPatterns, data.LegacyPatterns)
 	if err != nil {
 		return nil, fmt.Errorf("ignorer error: %s", err.Error())
 	}
 
-	passer, err := NewPasser(data.PassThrough)
+	passer, err := NewPasser(data.PassThrough, data.LegacyPatterns)
 	if err != nil {
 		return nil, fmt.Errorf("passer error: %s", err.Error())
 	}
 
-	variables, err := NewVariables(data.Variables)
+	variables, err := newVariables(data.Variables, data.LegacyNames)
 	if err != nil {
 		return nil, fmt.Errorf("variables error: %s", err.Error())
 	}
+	variables, err = assignGroups(data.Groups, variables, data.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	ruler, err := newRuler(data)
+	if err != nil {
+		return nil, err
+	}
+	declared := map[string]struct{}{}
+	for _, v := range variables {
+		declared[v.root()] = struct{}{}
+	}
+	for _, cd := range data.Components {
+		declared[cd.Name] = struct{}{}
+	}
+	if data.Metadata != nil {
+		if _, ok := declared[metadataNamespace]; ok {
+			return nil, fmt.Errorf("metadata error: `%s` is reserved for the metadata, it can't name a variable or a component", metadataNamespace)
+		}
+	}
+	if len(data.Data) > 0 {
+		if _, ok := declared[dataNamespace]; ok {
+			return nil, fmt.Errorf("data error: `%s` is reserved for the data files, it can't name a variable or a component", dataNamespace)
+		}
+	}
+	for _, rule := range ruler.rules {
+		if err := rule.validateFields(declared); err != nil {
+			return nil, err
+		}
+	}
+
+	if data.Metadata != nil {
+		declared[metadataNamespace] = struct{}{}
+	}
+	if len(data.Data) > 0 {
+		declared[dataNamespace] = struct{}{}
+	}
+	message, err := newMessage(data.Message, declared)
+	if err != nil {
+		return nil, err
+	}
+
+	requirements, err := newRequirements(data.Requires)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := newMinCLIVersion(data.MinCLIVersion); err != nil {
+		return nil, err
+	}
+
+	presets, err := newPresets(data.Presets, variables)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateDataFiles(data.Data, variables); err != nil {
+		return nil, err
+	}
+
+	translations := groupTranslations(data.Groups)
+	for _, vd := range data.Variables {
+		translations["variables."+vd.Name+".description"] = vd.Description
+		translations["variables."+vd.Name+".help"] = vd.Help
+	}
+	translations["message"] = data.Message
+	if err := validateTranslations(translations); err != nil {
+		return nil, err
+	}
+
+	spec := &Spec{
+		Metadata:         data.Metadata,
+		Ignorer:          ignorer,
+		Passer:           passer,
+		Ruler:            ruler,
+		Variables:        variables,
+		componentsData:   data.Components,
+		honorIgnoreFiles: data.HonorIgnoreFiles == nil || *data.HonorIgnoreFiles,
+		message:          message,
+		requirements:     requirements,
+		presets:          presets,
+		dataFiles:        data.Data,
+	}
+	// the presets of the variables with enum-from are checked once LoadData resolves their choices
+	if len(data.Data) == 0 {
+		if err := spec.validatePresets(); err != nil {
+			return nil, err
+		}
+	}
+	return spec, nil
+}
+
+// SetLanguage sets the language of the prompts and of the message, for the template and its components.
+// the texts without a translation in the language fall back to english
+func (s *Spec) SetLanguage(lang string) {
+	s.lang = lang
+	for i := range s.Variables {
+		s.Variables[i].lang = lang
+		if g := s.Variables[i].group; g != nil {
+			g.lang = lang
+		}
+	}
+	for _, c := range s.Components {
+		c.Spec.SetLanguage(lang)
+	}
+}
+
+// InputFromFile loads the template variables, and the ones of its components, from the input file.
+// component variables are nested under the component name.
+// the file values override the ones of the preset, if any
+func (s *Spec) InputFromFile(inputYAML string, preset string) (common.InputVariables, error) {
+	presetValues, err := s.preset(preset)
+	if err != nil {
+		return nil, err
+	}
+	values, err := readInputFile(inputYAML)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range presetValues {
+		v := s.Variables.get(name)
+		if _, ok := v.lookup(values); ok {
+			continue
+		}
+		if err := v.set(values, value); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.fromInput(values)
+}
 
-	return &Spec{
-		Ignorer:   ignorer,
-		Passer:    passer,
-		Variables: variables,
-	}, nil
+// InputFromStdIn prompts the user for the template variables, and the ones of its components.
+// the variables set by the preset, if any, are not prompted
+func (s *Spec) InputFromStdIn(preset string) (common.InputVariables, error) {
+	values, err := s.preset(preset)
+	if err != nil {
+		return nil, err
+	}
+	return s.fromReader(newStdinReader(), values)
+}
+
+func (s *Spec) fromInput(values common.InputVariables) (common.InputVariables, error) {
+	input, err := s.Variables.fromInput(values)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range s.Components {
+		componentValues, err := toInputVariables(values[c.Name])
+		if err != nil {
+			return nil, fmt.Errorf("component error [%s]: %s", c.Name, err.Error())
+		}
+		bound, err := c.bound(input)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range bound {
+			componentValues[name] = value
+		}
+
+		input[c.Name], err = c.Spec.fromInput(componentValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return input, nil
+}
+
+func (s *Spec) fromReader(r *lineReader, provided common.InputVariables) (common.InputVariables, error) {
+	input, err := s.Variables.fromReader(r, provided)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range s.Components {
+		bound, err := c.bound(input)
+		if err != nil {
+			return nil, err
+		}
+
+		input[c.Name], err = c.Spec.fromReader(r, bound)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return input, nil
+}
+
+// toInputVariables converts the nested maps decoded from yaml files
+func toInputVariables(value interface{}) (common.InputVariables, error) {
+	switch m := value.(type) {
+	case nil:
+		return common.InputVariables{}, nil
+	case common.InputVariables:
+		return m, nil
+	case map[string]interface{}:
+		return m, nil
+	case map[interface{}]interface{}:
+		input := common.InputVariables{}
+		for k, v := range m {
+			input[fmt.Sprintf("%v", k)] = v
+		}
+		return input, nil
+	default:
+		return nil, fmt.Errorf("expected a map of variables, found %v", value)
+	}
 }
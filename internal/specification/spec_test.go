@@ -1,12 +1,22 @@
 package specification
 
 import (
+	"io"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 )
 
+func newFromReader(r io.Reader, overwrite bool) (*Spec, error) {
+	data, err := decodeReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	return New(data, overwrite)
+}
+
 func Test_newFromReader(t *testing.T) {
 	yml := `---
 ignore:
@@ -69,3 +79,125 @@ variables:
 		})
 	}
 }
+
+func Test_newFromReader_versions(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+	}{
+		{
+			name: "unversioned pass-through alias",
+			yml: `---
+passed-through:
+  - myFileC.cpp
+`,
+		},
+		{
+			name: "version 2",
+			yml: `---
+version: 2
+pass-through:
+  - myFileC.cpp
+`,
+		},
+		{
+			name: "latest",
+			yml: `---
+version: 3
+pass-through:
+  - myFileC.cpp
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			require.True(t, s.Passer.Pass("myFileC.cpp"))
+		})
+	}
+}
+
+func Test_newFromReader_legacyPatterns(t *testing.T) {
+	tests := []struct {
+		name    string
+		yml     string
+		ignored bool
+	}{
+		{
+			name:    "version 2",
+			yml:     "version: 2\nignore:\n  - \"*.log\"\n",
+			ignored: false,
+		},
+		{
+			name:    "latest",
+			yml:     "version: 3\nignore:\n  - \"*.log\"\n",
+			ignored: true,
+		},
+		{
+			name:    "latest with legacy patterns",
+			yml:     "version: 3\nlegacy-patterns: true\nignore:\n  - \"*.log\"\n",
+			ignored: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.Nil(t, err)
+			require.Equal(t, tt.ignored, s.Ignorer.Ignore("logs/a.log"))
+		})
+	}
+}
+
+func Test_newFromReader_error(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		expected string
+	}{
+		{
+			name: "unknown key",
+			yml: `---
+version: 2
+ignore:
+  - myfileA.py
+passed-through:
+  - myFileC.cpp
+`,
+			expected: "line 5",
+		},
+		{
+			name: "unknown variable key",
+			yml: `---
+variables:
+  - name: x
+    typ: string
+`,
+			expected: "line 4",
+		},
+		{
+			name: "newer version",
+			yml: `---
+version: 99
+`,
+			expected: "upgrade",
+		},
+		{
+			name: "invalid version",
+			yml: `---
+version: -1
+`,
+			expected: "invalid version",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
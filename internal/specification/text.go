@@ -0,0 +1,115 @@
+package specification
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"copy-basta/internal/common"
+)
+
+// A Text is a plain string, or its translations keyed by language
+type Text map[string]string
+
+// plainText is the key of the texts that are not translated
+const plainText = ""
+
+func newText(s string) *Text {
+	return &Text{plainText: s}
+}
+
+func (t *Text) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var s string
+	if err := unmarshal(&s); err == nil {
+		*t = Text{plainText: s}
+		return nil
+	}
+	var translations map[string]string
+	if err := unmarshal(&translations); err != nil {
+		return errors.New("expected a string or a map of translations keyed by language")
+	}
+	if len(translations) == 0 {
+		return errors.New("expected at least one translation")
+	}
+	for lang := range translations {
+		if lang == plainText {
+			return errors.New("translations must be keyed by a language")
+		}
+	}
+	*t = translations
+	return nil
+}
+
+// key returns the key of the translation to use for the language. it falls back to the
+// language without region (pt_BR to pt), to the default language, then to the first translation
+func (t Text) key(lang string) string {
+	candidates := []string{lang, strings.Split(lang, "_")[0], common.DefaultLanguage, plainText}
+	for _, candidate := range candidates {
+		if _, ok := t[candidate]; ok {
+			return candidate
+		}
+	}
+	keys := t.languages()
+	if len(keys) == 0 {
+		return plainText
+	}
+	return keys[0]
+}
+
+func (t Text) in(lang string) string {
+	return t[t.key(lang)]
+}
+
+// languages returns the sorted languages of the translations
+func (t Text) languages() []string {
+	var languages []string
+	for lang := range t {
+		if lang != plainText {
+			languages = append(languages, lang)
+		}
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// validateTranslations checks that, when the spec translates its texts, every text, by field, is translated
+// in the same languages, english included. plain texts are missing their translations
+func validateTranslations(texts map[string]*Text) error {
+	all := map[string]struct{}{}
+	for _, t := range texts {
+		if t == nil {
+			continue
+		}
+		for _, lang := range t.languages() {
+			all[lang] = struct{}{}
+		}
+	}
+	if len(all) == 0 {
+		return nil
+	}
+	all[common.DefaultLanguage] = struct{}{}
+
+	var fields []string
+	for field := range texts {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		t := texts[field]
+		if t == nil {
+			continue
+		}
+		var missing []string
+		for lang := range all {
+			if _, ok := (*t)[lang]; !ok {
+				missing = append(missing, lang)
+			}
+		}
+		if len(missing) > 0 {
+			sort.Strings(missing)
+			return fmt.Errorf("translation error [%s]: missing %s", field, strings.Join(missing, ", "))
+		}
+	}
+	return nil
+}
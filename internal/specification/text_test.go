@@ -0,0 +1,155 @@
+package specification
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"copy-basta/internal/common"
+)
+
+func Test_Text_in(t *testing.T) {
+	translated := Text{"en": "your name", "pt": "seu nome", "pt_PT": "o seu nome"}
+	tests := []struct {
+		name     string
+		text     Text
+		lang     string
+		expected string
+	}{
+		{name: "plain", text: *newText("your name"), lang: "pt", expected: "your name"},
+		{name: "language", text: translated, lang: "pt", expected: "seu nome"},
+		{name: "region", text: translated, lang: "pt_PT", expected: "o seu nome"},
+		{name: "language of region", text: translated, lang: "pt_BR", expected: "seu nome"},
+		{name: "english fallback", text: translated, lang: "it", expected: "your name"},
+		{name: "first fallback", text: Text{"it": "il tuo nome", "de": "dein Name"}, lang: "fr", expected: "dein Name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.text.in(tt.lang))
+		})
+	}
+}
+
+func Test_Spec_translations(t *testing.T) {
+	spec, err := newFromReader(strings.NewReader(`---
+version: 3
+groups:
+  - name: db
+    title:
+      en: Database
+      pt: Banco de dados
+variables:
+  - name: name
+    type: string
+    description:
+      en: your name
+      pt: seu nome
+    help:
+      en: any name will do
+      pt: qualquer nome serve
+  - name: host
+    type: string
+    description:
+      en: db host
+      pt: host do banco
+    group: db
+message:
+  en: "{{ .name }} generated"
+  pt: "{{ .name }} gerado"
+`), false)
+	require.Nil(t, err)
+
+	spec.SetLanguage("pt_BR")
+	name := spec.Variables.get("name")
+	require.Contains(t, name.prompt(), "seu nome")
+	require.Equal(t, "qualquer nome serve", name.Help())
+	require.Contains(t, spec.Variables.get("host").group.header(), "Banco de dados")
+	message, err := spec.Message(common.InputVariables{"name": "api"})
+	require.Nil(t, err)
+	require.Equal(t, "api gerado", message)
+
+	spec.SetLanguage("it")
+	require.Contains(t, name.prompt(), "your name")
+	require.Contains(t, spec.Variables.get("host").prompt(), "db host")
+}
+
+func Test_Spec_translations_error(t *testing.T) {
+	tests := []struct {
+		name     string
+		yml      string
+		expected string
+	}{
+		{
+			name: "missing translation",
+			yml: `---
+version: 3
+variables:
+  - name: name
+    description:
+      en: your name
+      pt: seu nome
+  - name: age
+    description:
+      en: your age
+`,
+			expected: "translation error [variables.age.description]: missing pt",
+		},
+		{
+			name: "missing message translation",
+			yml: `---
+version: 3
+groups:
+  - name: db
+    ask:
+      en: use a database?
+      it: usare un database?
+message:
+  en: done
+`,
+			expected: "translation error [message]: missing it",
+		},
+		{
+			name: "missing english",
+			yml: `---
+version: 3
+variables:
+  - name: name
+    description:
+      pt: seu nome
+`,
+			expected: "translation error [variables.name.description]: missing en",
+		},
+		{
+			name: "plain text",
+			yml: `---
+version: 3
+variables:
+  - name: name
+    description:
+      en: your name
+      pt: seu nome
+  - name: age
+    description: your age
+`,
+			expected: "translation error [variables.age.description]: missing en, pt",
+		},
+		{
+			name: "not a text",
+			yml: `---
+version: 3
+variables:
+  - name: name
+    description: [your, name]
+`,
+			expected: "expected a string or a map of translations keyed by language",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newFromReader(strings.NewReader(tt.yml), false)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -1,14 +1,13 @@
 package specification
 
 import (
-	"bufio"
 	"errors"
 	"fmt"
 	"io/ioutil"
-	"os"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"copy-basta/internal/common/log"
 
@@ -46,28 +45,97 @@ type Variables []Variable
 type Variable struct {
 	name        string
 	dtype       *string
+	format      *string
 	defaultVal  interface{}
-	description *string
+	defaultTmpl *expression
+	description *Text
+	help        *Text
+	when        *expression
+	value       *expression
+	secret      bool
+	optional    bool
+	enum        []interface{}
+	enumFrom    *string
+	group       *Group
+	maxSize     *int64
+	encoding    *string
+	// lang is the language of the prompts
+	lang string
+	// namespace is set for the variables of template components
+	namespace string
+	// legacyName is set for the variables of the specifications before version 3, whose names are input keys
+	legacyName bool
 }
 
-func NewVariables(varData []VariableData) (Variables, error) {
+func newVariables(varData []VariableData, legacyNames bool) (Variables, error) {
 	vars := Variables{}
 	for _, vd := range varData {
 		v := Variable{
+			legacyName:  legacyNames,
 			name:        vd.Name,
 			dtype:       vd.DType,
+			format:      vd.Format,
 			defaultVal:  vd.DefaultVal,
 			description: vd.Description,
+			help:        vd.Help,
+			secret:      vd.Secret,
+			optional:    vd.Required != nil && !*vd.Required,
+			enum:        vd.Enum,
+			enumFrom:    vd.EnumFrom,
+			encoding:    vd.Encoding,
+		}
+		if vd.MaxSize != nil {
+			maxSize, err := parseSize(*vd.MaxSize)
+			if err != nil {
+				return nil, fmt.Errorf("variable error [max-size]: %s", err.Error())
+			}
+			v.maxSize = &maxSize
+		}
+		if s, ok := vd.DefaultVal.(string); ok && strings.Contains(s, "{{") {
+			defaultTmpl, err := newExpression(vd.Name, s)
+			if err != nil {
+				return nil, fmt.Errorf("variable error [default]: %s", err.Error())
+			}
+			v.defaultVal = nil
+			v.defaultTmpl = defaultTmpl
+		}
+		if vd.When != nil {
+			when, err := newExpression(vd.Name, *vd.When)
+			if err != nil {
+				return nil, fmt.Errorf("variable error [when]: %s", err.Error())
+			}
+			v.when = when
+		}
+		if vd.Value != nil {
+			value, err := newExpression(vd.Name, *vd.Value)
+			if err != nil {
+				return nil, fmt.Errorf("variable error [value]: %s", err.Error())
+			}
+			v.value = value
 		}
 		if err := v.validate(); err != nil {
 			return nil, err
 		}
 		vars = append(vars, v)
 	}
-	return vars, nil
+	if !legacyNames {
+		if err := vars.validateNames(); err != nil {
+			return nil, err
+		}
+	}
+	return sortVariables(vars)
+}
+
+func (vars Variables) get(name string) *Variable {
+	for i := range vars {
+		if vars[i].name == name {
+			return &vars[i]
+		}
+	}
+	return nil
 }
 
-func (vars Variables) InputFromFile(inputYAML string) (common.InputVariables, error) {
+func readInputFile(inputYAML string) (common.InputVariables, error) {
 	yamlFile, err := ioutil.ReadFile(inputYAML)
 	if err != nil {
 		return nil, err
@@ -78,16 +146,37 @@ func (vars Variables) InputFromFile(inputYAML string) (common.InputVariables, er
 	if err != nil {
 		return nil, err
 	}
+	return input, nil
+}
 
-	for _, v := range vars {
-		value, ok := input[v.name]
-		if !ok {
-			if v.defaultVal != nil {
-				return nil, fmt.Errorf("no value nor default for %s", v.name)
+func (vars Variables) fromInput(input common.InputVariables) (common.InputVariables, error) {
+	for _, declared := range vars {
+		v, err := declared.withDefault(input)
+		if err != nil {
+			return nil, err
+		}
+
+		value, derived, err := v.derive(input)
+		if err != nil {
+			return nil, err
+		}
+		if derived {
+			if _, provided := v.lookup(input); provided {
+				log.L.DebugWithData("variable is not an input, ignoring provided value", log.Data{"name": v.name})
 			}
-			input[v.name] = v.defaultVal
+		} else {
+			value, err = v.providedValue(input)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		typed, err := v.typed(value)
+		if err != nil {
+			return nil, err
 		}
-		if err := v.valueOk(value); err != nil {
+		v.redact(typed)
+		if err := v.set(input, typed); err != nil {
 			return nil, err
 		}
 	}
@@ -95,47 +184,89 @@ func (vars Variables) InputFromFile(inputYAML string) (common.InputVariables, er
 	return input, nil
 }
 
-func (vars Variables) InputFromStdIn() (common.InputVariables, error) {
-	r := bufio.NewReader(os.Stdin)
+// fromReader prompts the user for the variables values.
+// the provided values (bound by a parent template, for instance) are not prompted
+func (vars Variables) fromReader(r *lineReader, provided common.InputVariables) (common.InputVariables, error) {
 	fmt.Print("\n")
 	inputVars := common.InputVariables{}
-	for _, v := range vars {
-		for retry := 3; retry > 0; retry-- {
-			userInput, err := v.promptLoop(r)
-			if err != nil {
-				return nil, err
-			}
+	sections := newSections()
+	for _, declared := range vars {
+		v, err := declared.withDefault(inputVars)
+		if err != nil {
+			return nil, err
+		}
 
-			if userInput != nil {
-				value, err := v.fromString(*userInput)
-				if err != nil {
-					if retry > 1 {
-						fmt.Println(v.Help())
-						continue
-					}
-					return nil, err
-				}
-				inputVars[v.name] = value
+		value, derived, err := v.derive(inputVars)
+		if err != nil {
+			return nil, err
+		}
+		if !derived {
+			if _, ok := v.lookup(provided); ok {
+				value, err = v.providedValue(provided)
 			} else {
-				inputVars[v.name] = v.defaultVal
+				value, err = v.groupedPromptValue(r, sections)
+			}
+			if err != nil {
+				return nil, err
 			}
-			break
 		}
 
+		typed, err := v.typed(value)
+		if err != nil {
+			return nil, err
+		}
+		v.redact(typed)
+		if err := v.set(inputVars, typed); err != nil {
+			return nil, err
+		}
 	}
 	return inputVars, nil
 }
 
+// providedValue returns the variable value from the input file, falling back to the default
+func (v *Variable) providedValue(input common.InputVariables) (interface{}, error) {
+	value, ok := v.lookup(input)
+	if b, isBound := value.(boundValue); isBound {
+		return b.value, nil
+	}
+	if ok {
+		v.redact(value)
+	}
+	if !ok || value == nil {
+		switch {
+		case v.defaultVal != nil:
+			value = v.defaultVal
+		case v.optional:
+			return v.zeroValue(), nil
+		default:
+			return nil, fmt.Errorf("no value nor default for %s", v.qualifiedName())
+		}
+	}
+	if err := v.valueOk(value); err != nil {
+		return nil, err
+	}
+	if v.isPath() {
+		return v.pathValue(value.(string))
+	}
+	return value, nil
+}
+
 func (v *Variable) validate() error {
 	// name checks
 	if v.name == "" {
 		return errors.New("variable error [name]: is required")
 	}
+	if err := validateName(v.name); err != nil {
+		if !v.legacyName {
+			return err
+		}
+		log.L.WarnWithData("variable name is not a template identifier, templates can only use it with index", log.Data{"name": v.name})
+	}
 
 	// type checks
 	if v.dtype != nil {
 		if ok := func(actualType string) bool {
-			for _, candidateType := range openAPITypes {
+			for _, candidateType := range append(append([]string{}, openAPITypes...), pathTypes...) {
 				if actualType == candidateType {
 					return true
 				}
@@ -143,12 +274,45 @@ func (v *Variable) validate() error {
 			return false
 		}(*v.dtype); !ok {
 			return fmt.Errorf(`variable error [type]: %s is not a valid type. 
-only open-api types are supported (https://swagger.io/docs/specification/data-models/data-types)`, *v.dtype)
+only open-api types, file and directory are supported (https://swagger.io/docs/specification/data-models/data-types)`, *v.dtype)
 		}
 	} else {
 		log.L.WarnWithData("spec variable without type, defaulting to any", log.Data{"name": v.name})
 	}
 
+	// format checks
+	if v.format != nil {
+		if v.dtype == nil || *v.dtype != openAPIString {
+			return errors.New("variable error [format]: only string variables can have a format")
+		}
+		if _, ok := stringFormats[*v.format]; !ok {
+			return fmt.Errorf("variable error [format]: %s is not a valid format", *v.format)
+		}
+	}
+
+	// secret checks, other values (`true`, `1`) would be redacted wherever they are logged
+	if v.secret && (v.dtype == nil || (*v.dtype != openAPIString && *v.dtype != pathFile)) {
+		return errors.New("variable error [secret]: only string and file variables can be secret")
+	}
+
+	// path checks
+	if err := v.validatePath(); err != nil {
+		return err
+	}
+
+	// value checks
+	if v.value != nil && (v.defaultVal != nil || v.defaultTmpl != nil) {
+		return errors.New("variable error [value]: computed variables can't have a default")
+	}
+
+	// enum checks
+	if v.enum != nil && v.enumFrom != nil {
+		return errors.New("variable error [enum]: enum and enum-from are mutually exclusive")
+	}
+	if err := v.validateEnum(); err != nil {
+		return err
+	}
+
 	// default checks
 	if v.defaultVal != nil {
 		if err := v.valueOk(v.defaultVal); err != nil {
@@ -159,11 +323,171 @@ only open-api types are supported (https://swagger.io/docs/specification/data-mo
 	return nil
 }
 
+// redact hides the value of secret variables from the logs, and with it the values derived from it
+func (v *Variable) redact(value interface{}) {
+	if !v.secret || value == nil {
+		return
+	}
+	log.L.Redact(fmt.Sprintf("%v", value))
+}
+
+// qualifiedName is the variable name prefixed by its namespace, as in `db.host`
+func (v *Variable) qualifiedName() string {
+	return qualify(v.namespace, v.name)
+}
+
+// withDefault returns a copy of the variable with its templated default
+// rendered against the input collected so far
+func (v Variable) withDefault(input common.InputVariables) (Variable, error) {
+	if v.defaultTmpl == nil {
+		return v, nil
+	}
+	value, err := v.renderValue(v.defaultTmpl, input)
+	if err != nil {
+		return v, fmt.Errorf("variable error [default]: %s", err.Error())
+	}
+	v.defaultVal = value
+	v.defaultTmpl = nil
+	return v, nil
+}
+
+// derive returns the value of variables that are not collected from the user:
+// the ones disabled by their `when` expression and the computed ones
+func (v *Variable) derive(input common.InputVariables) (interface{}, bool, error) {
+	enabled, err := v.enabled(input)
+	if err != nil {
+		return nil, false, err
+	}
+	if !enabled {
+		return v.disabledValue(), true, nil
+	}
+	if v.value != nil {
+		value, err := v.renderValue(v.value, input)
+		if err != nil {
+			return nil, false, fmt.Errorf("variable error [value]: %s", err.Error())
+		}
+		return value, true, nil
+	}
+	return nil, false, nil
+}
+
+// renderValue renders the expression and parses the result into the variable type
+func (v *Variable) renderValue(e *expression, input common.InputVariables) (interface{}, error) {
+	s, err := e.render(input)
+	if err != nil {
+		return nil, err
+	}
+	value, err := v.fromString(s)
+	if err != nil {
+		return nil, err
+	}
+	if err := v.valueOk(value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// enabled evaluates the variable `when` expression against the input collected so far
+func (v *Variable) enabled(input common.InputVariables) (bool, error) {
+	if v.when == nil {
+		return true, nil
+	}
+	enabled, err := v.when.isTrue(input)
+	if err != nil {
+		return false, fmt.Errorf("variable error [when]: %s", err.Error())
+	}
+	return enabled, nil
+}
+
+// disabledValue is the value of a variable whose `when` expression is false
+func (v *Variable) disabledValue() interface{} {
+	if v.defaultVal != nil {
+		return v.defaultVal
+	}
+	return v.zeroValue()
+}
+
+func (v *Variable) zeroValue() interface{} {
+	if v.dtype == nil {
+		return nil
+	}
+	if f, ok := v.stringFormat(); ok && f.parse != nil {
+		return nil
+	}
+	switch *v.dtype {
+	case openAPIString, pathFile:
+		return ""
+	case pathDirectory:
+		return []string{}
+	case openAPINumber:
+		return float64(0)
+	case openAPIInteger:
+		return 0
+	case openAPIBoolean:
+		return false
+	case openAPIArray:
+		return []interface{}{}
+	case openAPIObject:
+		return map[interface{}]interface{}{}
+	default:
+		log.L.DebugWithData("default case should not run", log.Data{"name": v.name, "type": *v.dtype})
+		return nil
+	}
+}
+
 func (v *Variable) valueOk(value interface{}) error {
+	if err := v.typeOk(value); err != nil {
+		return err
+	}
+	return v.inEnum(value)
+}
+
+// validateEnum checks the enum choices are values of the variable type
+func (v *Variable) validateEnum() error {
+	for _, choice := range v.enum {
+		if err := v.typeOk(choice); err != nil {
+			return fmt.Errorf("variable error [enum]: %s", err.Error())
+		}
+	}
+	return nil
+}
+
+func (v *Variable) inEnum(value interface{}) error {
+	if len(v.enum) == 0 {
+		return nil
+	}
+	for _, choice := range v.enum {
+		if fmt.Sprintf("%v", choice) == fmt.Sprintf("%v", value) {
+			return nil
+		}
+	}
+	return fmt.Errorf("value error: `%v` is not one of %s", value, v.choices())
+}
+
+// choices returns the enum choices as displayed to the user
+func (v *Variable) choices() string {
+	var choices []string
+	for _, choice := range v.enum {
+		choices = append(choices, fmt.Sprintf("%v", choice))
+	}
+	return "[" + strings.Join(choices, ", ") + "]"
+}
+
+func (v *Variable) typeOk(value interface{}) error {
 	if v.dtype == nil {
 		return nil
 	}
 
+	if value == nil {
+		return fmt.Errorf("value error: no value provided. variable type is %s", *v.dtype)
+	}
+
+	if _, ok := value.(time.Time); ok {
+		if f, ok := v.stringFormat(); ok && f.parse != nil {
+			return nil
+		}
+	}
+
 	actualKind := reflect.TypeOf(value).Kind()
 
 	isOneOF := func(actual reflect.Kind, accepted []reflect.Kind) error {
@@ -179,7 +503,7 @@ func (v *Variable) valueOk(value interface{}) error {
 	var acceptedKinds []reflect.Kind
 
 	switch *v.dtype {
-	case openAPIString:
+	case openAPIString, pathFile, pathDirectory:
 		acceptedKinds = []reflect.Kind{reflect.String}
 	case openAPINumber:
 		acceptedKinds = []reflect.Kind{reflect.Int, reflect.Float64}
@@ -197,44 +521,124 @@ func (v *Variable) valueOk(value interface{}) error {
 only open-api types are supported (https://swagger.io/docs/specification/data-models/data-types)`, *v.dtype)
 	}
 
-	return isOneOF(actualKind, acceptedKinds)
+	if err := isOneOF(actualKind, acceptedKinds); err != nil {
+		return err
+	}
+
+	if f, ok := v.stringFormat(); ok {
+		if err := f.check(reflect.ValueOf(value).String()); err != nil {
+			return fmt.Errorf("value error: `%v` is not a valid %s (%s)", value, *v.format, err.Error())
+		}
+	}
+	return nil
+}
+
+func (v *Variable) stringFormat() (stringFormat, bool) {
+	if v.format == nil {
+		return stringFormat{}, false
+	}
+	f, ok := stringFormats[*v.format]
+	return f, ok
+}
+
+// typed converts a valid value into the representation given to the templates
+func (v *Variable) typed(value interface{}) (interface{}, error) {
+	s, isString := value.(string)
+	if f, ok := v.stringFormat(); ok && f.parse != nil && isString {
+		return f.parse(s)
+	}
+	return value, nil
 }
 
-func (v *Variable) promptLoop(r *bufio.Reader) (*string, error) {
+// groupedPromptValue prompts the variable in its group section. the variables of skipped groups
+// get the value of disabled variables
+func (v *Variable) groupedPromptValue(r *lineReader, sections *sections) (interface{}, error) {
+	enabled, err := sections.enter(r, v.group)
+	if err != nil {
+		return nil, err
+	}
+	if !enabled {
+		return v.disabledValue(), nil
+	}
+	return v.promptValue(r)
+}
+
+func (v *Variable) promptValue(r *lineReader) (interface{}, error) {
+	for retry := 3; retry > 0; retry-- {
+		userInput, err := v.promptLoop(r)
+		if err != nil {
+			return nil, err
+		}
+
+		if userInput == nil {
+			if v.defaultVal != nil {
+				return v.defaultVal, nil
+			}
+			return v.zeroValue(), nil
+		}
+
+		value, err := v.fromString(*userInput)
+		if err == nil {
+			err = v.valueOk(value)
+		}
+		if err == nil && v.isPath() {
+			value, err = v.pathValue(value.(string))
+		}
+		if err != nil {
+			if retry > 1 {
+				fmt.Println(v.Help())
+				continue
+			}
+			return nil, err
+		}
+		return value, nil
+	}
+	return nil, errors.New("variable error: too many retries")
+}
+
+func (v *Variable) promptLoop(r *lineReader) (*string, error) {
 	for {
 		fmt.Print(v.prompt())
-		userInput, err := r.ReadString('\n')
+		userInput, err := r.readLine(v.secret)
 		fmt.Print("\n")
 		if err != nil {
 			return nil, err
 		}
-		userInput = strings.TrimSuffix(userInput, "\n")
 
 		if userInput != "" {
+			v.redact(userInput)
 			return &userInput, nil
 		}
 
-		if v.defaultVal != nil {
+		if v.defaultVal != nil || v.optional {
 			return nil, nil
 		}
+		fmt.Println("a value is required")
 	}
 }
 
 func (v *Variable) prompt() string {
 	sBuilder := strings.Builder{}
 	qMark := common.ColoredFormat(common.ColorOrange, common.TextFormatBold, common.BGColorNone, "?")
-	coloredName := common.ColoredFormat(common.ColorGreen, common.TextFormatBold, common.BGColorNone, v.name)
+	coloredName := common.ColoredFormat(common.ColorGreen, common.TextFormatBold, common.BGColorNone, v.qualifiedName())
 	vType := func() string {
 		if v.dtype != nil {
 			return *v.dtype
 		}
 		return "any"
 	}()
+
+	if v.optional {
+		vType = fmt.Sprintf("%s, optional", vType)
+	}
+	if len(v.enum) > 0 {
+		vType = fmt.Sprintf("%s, one of %s", vType, v.choices())
+	}
 	coloredType := common.ColoredFormat(common.ColorCyan, common.TextFormatBold, common.BGColorNone, vType)
 
 	if v.description != nil {
 		coloredDescription := common.ColoredFormat(
-			common.ColorGreen, common.TextFormatNormal, common.BGColorNone, *v.description,
+			common.ColorGreen, common.TextFormatNormal, common.BGColorNone, v.description.in(v.lang),
 		)
 		sBuilder.WriteString(fmt.Sprintf("%s [%s] ", coloredDescription, coloredType))
 	} else {
@@ -243,7 +647,12 @@ func (v *Variable) prompt() string {
 
 	sBuilder.WriteString("\n")
 
-	if v.defaultVal != nil {
+	if v.defaultVal != nil && v.secret {
+		coloredDefault := common.ColoredFormat(
+			common.ColorOrange, common.TextFormatNormal, common.BGColorNone, log.Redacted,
+		)
+		sBuilder.WriteString(fmt.Sprintf("%s %s [%s]    ", qMark, coloredName, coloredDefault))
+	} else if v.defaultVal != nil {
 		defaultS, err := v.toString(v.defaultVal)
 		if err != nil {
 			log.L.DebugWithData(
@@ -272,7 +681,7 @@ func (v *Variable) fromString(s string) (interface{}, error) {
 	var err error
 
 	switch *v.dtype {
-	case openAPIString:
+	case openAPIString, pathFile, pathDirectory:
 		value = s
 	case openAPINumber:
 		value, err = strconv.ParseFloat(s, 64)
@@ -312,7 +721,7 @@ func (v *Variable) toString(value interface{}) (string, error) {
 		return fmt.Sprintf("%v", value), nil
 	}
 	switch *v.dtype {
-	case openAPIString:
+	case openAPIString, pathFile, pathDirectory:
 		fallthrough
 	case openAPINumber:
 		fallthrough
@@ -350,12 +759,25 @@ only open-api types are supported (https://swagger.io/docs/specification/data-mo
 }
 
 func (v *Variable) Help() string {
+	if v.help != nil {
+		return v.help.in(v.lang)
+	}
+	if len(v.enum) > 0 {
+		return fmt.Sprintf("input must be one of %s", v.choices())
+	}
 	if v.dtype == nil {
 		return "input is not type, anything will do"
 	}
+	if f, ok := v.stringFormat(); ok {
+		return fmt.Sprintf("input must be a string in the %s format. example: `%s`", *v.format, f.example)
+	}
 	switch *v.dtype {
 	case openAPIString:
 		return "input must be a string. example: `pizza`"
+	case pathFile:
+		return "input must be the path of an existing file. example: `schema.sql`"
+	case pathDirectory:
+		return "input must be the path of an existing directory. example: `migrations`"
 	case openAPINumber:
 		return "input must be a number. example: `3.14`"
 	case openAPIInteger:
@@ -1,9 +1,15 @@
 package specification
 
 import (
+	"os"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+
+	"copy-basta/internal/common"
+	"copy-basta/internal/common/log"
 )
 
 func newSpecVar(t string) Variable {
@@ -141,7 +147,7 @@ func Test_SpecVariable_validate(t *testing.T) {
 				name:        "complete",
 				dtype:       func() *string { v := openAPIInteger; return &v }(),
 				defaultVal:  2289,
-				description: func() *string { s := "a legit integer"; return &s }(),
+				description: newText("a legit integer"),
 			},
 		},
 	}
@@ -182,7 +188,22 @@ func Test_SpecVariable_validate_error(t *testing.T) {
 				name:        "myName",
 				dtype:       func() *string { v := openAPIBoolean; return &v }(),
 				defaultVal:  44,
-				description: func() *string { s := "a boolean, therefore not a integer"; return &s }(),
+				description: newText("a boolean, therefore not a integer"),
+			},
+		},
+		{
+			name: "secret boolean",
+			specVar: Variable{
+				name:   "myName",
+				dtype:  func() *string { v := openAPIBoolean; return &v }(),
+				secret: true,
+			},
+		},
+		{
+			name: "secret without type",
+			specVar: Variable{
+				name:   "myName",
+				secret: true,
 			},
 		},
 	}
@@ -229,7 +250,7 @@ func Test_SpecVariable_prompt(t *testing.T) {
 				name:        "myVariable",
 				dtype:       &myType,
 				defaultVal:  nil,
-				description: func() *string { s := "my template variable description 1"; return &s }(),
+				description: newText("my template variable description 1"),
 			},
 			expectedIn: []string{"my template variable description 1", "myType", "?", "myVariable"},
 		},
@@ -239,7 +260,7 @@ func Test_SpecVariable_prompt(t *testing.T) {
 				name:        "myVariable",
 				dtype:       &myType,
 				defaultVal:  "myDefault",
-				description: func() *string { s := "my template variable description 2"; return &s }(),
+				description: newText("my template variable description 2"),
 			},
 			expectedIn: []string{"my template variable description 2", "myType", "?", "myVariable", "myDefault"},
 		},
@@ -249,7 +270,7 @@ func Test_SpecVariable_prompt(t *testing.T) {
 				name:        "myVariable",
 				dtype:       nil,
 				defaultVal:  "myDefault",
-				description: func() *string { s := "my template variable description 3"; return &s }(),
+				description: newText("my template variable description 3"),
 			},
 			expectedIn: []string{"my template variable description 3", "?", "myVariable", "myDefault"},
 		},
@@ -319,3 +340,326 @@ func Test_SpecVariable_process(t *testing.T) {
 		})
 	}
 }
+
+func newTestVariables(t *testing.T, yml string) Variables {
+	data := SpecData{}
+	err := yaml.Unmarshal([]byte(yml), &data)
+	require.Nil(t, err)
+	vars, err := newVariables(data.Variables, false)
+	require.Nil(t, err)
+	return vars
+}
+
+func newTestVariablesError(t *testing.T, yml string) error {
+	data := SpecData{}
+	err := yaml.Unmarshal([]byte(yml), &data)
+	require.Nil(t, err)
+	_, err = newVariables(data.Variables, false)
+	return err
+}
+
+const whenVariablesYAML = `
+variables:
+  - name: useDatabase
+    type: boolean
+  - name: dbName
+    type: string
+    when: "{{ .useDatabase }}"
+  - name: dbPort
+    type: integer
+    default: 5432
+    when: "{{ .useDatabase }}"
+`
+
+func Test_Variables_fromInput_when(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    common.InputVariables
+		expected common.InputVariables
+	}{
+		{
+			name:     "enabled",
+			input:    common.InputVariables{"useDatabase": true, "dbName": "users", "dbPort": 3306},
+			expected: common.InputVariables{"useDatabase": true, "dbName": "users", "dbPort": 3306},
+		},
+		{
+			name:     "disabled",
+			input:    common.InputVariables{"useDatabase": false},
+			expected: common.InputVariables{"useDatabase": false, "dbName": "", "dbPort": 5432},
+		},
+		{
+			name:     "disabled ignores input",
+			input:    common.InputVariables{"useDatabase": false, "dbName": "users"},
+			expected: common.InputVariables{"useDatabase": false, "dbName": "", "dbPort": 5432},
+		},
+	}
+
+	vars := newTestVariables(t, whenVariablesYAML)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := vars.fromInput(tt.input)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, input)
+		})
+	}
+}
+
+func Test_Variables_fromInput_when_error(t *testing.T) {
+	vars := newTestVariables(t, whenVariablesYAML)
+	_, err := vars.fromInput(common.InputVariables{"useDatabase": true})
+	require.NotNil(t, err)
+}
+
+func Test_Variables_fromReader_when(t *testing.T) {
+	tests := []struct {
+		name     string
+		stdin    string
+		expected common.InputVariables
+	}{
+		{
+			name:     "enabled",
+			stdin:    "true\nusers\n\n",
+			expected: common.InputVariables{"useDatabase": true, "dbName": "users", "dbPort": 5432},
+		},
+		{
+			name:     "disabled",
+			stdin:    "false\n",
+			expected: common.InputVariables{"useDatabase": false, "dbName": "", "dbPort": 5432},
+		},
+	}
+
+	vars := newTestVariables(t, whenVariablesYAML)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input, err := vars.fromReader(newLineReader(strings.NewReader(tt.stdin)), nil)
+			require.Nil(t, err)
+			require.Equal(t, tt.expected, input)
+		})
+	}
+}
+
+func Test_newVariables_when_error(t *testing.T) {
+	_, err := newVariables([]VariableData{
+		{Name: "broken", When: func() *string { s := "{{ .useDatabase "; return &s }()},
+	}, false)
+	require.NotNil(t, err)
+}
+
+const computedVariablesYAML = `
+variables:
+  - name: serviceName
+    type: string
+  - name: service_name
+    type: string
+    value: "{{ .serviceName | snake }}"
+  - name: replicas
+    type: integer
+    value: "{{ len .serviceName }}"
+`
+
+func Test_Variables_computed(t *testing.T) {
+	expected := common.InputVariables{"serviceName": "userService", "service_name": "user_service", "replicas": 11}
+	vars := newTestVariables(t, computedVariablesYAML)
+
+	input, err := vars.fromInput(common.InputVariables{"serviceName": "userService", "service_name": "ignored"})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = vars.fromReader(newLineReader(strings.NewReader("userService\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+}
+
+func Test_Variables_computed_error(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: serviceName
+    type: string
+  - name: port
+    type: integer
+    value: "{{ .serviceName }}"
+`)
+	_, err := vars.fromInput(common.InputVariables{"serviceName": "userService"})
+	require.NotNil(t, err)
+}
+
+const templatedDefaultsYAML = `
+variables:
+  - name: module
+    type: string
+    default: "github.com/{{ .org }}/{{ .name }}"
+  - name: org
+    type: string
+    default: acme
+  - name: name
+    type: string
+  - name: port
+    type: integer
+    default: "{{ if eq .name \"api\" }}80{{ else }}8080{{ end }}"
+`
+
+func Test_Variables_templatedDefaults(t *testing.T) {
+	vars := newTestVariables(t, templatedDefaultsYAML)
+
+	var names []string
+	for _, v := range vars {
+		names = append(names, v.name)
+	}
+	require.Equal(t, []string{"org", "name", "module", "port"}, names)
+
+	input, err := vars.fromInput(common.InputVariables{"name": "api"})
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"org": "acme", "name": "api", "module": "github.com/acme/api", "port": 80}, input)
+
+	input, err = vars.fromReader(newLineReader(strings.NewReader("\nweb\n\n\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"org": "acme", "name": "web", "module": "github.com/acme/web", "port": 8080}, input)
+}
+
+func Test_Variables_templatedDefaults_error(t *testing.T) {
+	tests := []struct {
+		name string
+		yml  string
+	}{
+		{
+			name: "undeclared variable",
+			yml: `
+variables:
+  - name: module
+    type: string
+    default: "github.com/{{ .org }}"
+`,
+		},
+		{
+			name: "invalid template",
+			yml: `
+variables:
+  - name: module
+    type: string
+    default: "github.com/{{ .org "
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NotNil(t, newTestVariablesError(t, tt.yml))
+		})
+	}
+}
+
+func Test_Variables_secret(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: apiKey
+    type: string
+    secret: true
+    default: s3cr3t-default
+`)
+	require.NotContains(t, vars[0].prompt(), "s3cr3t-default")
+	require.Contains(t, vars[0].prompt(), log.Redacted)
+
+	input, err := vars.fromReader(newLineReader(strings.NewReader("s3cr3t-input\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"apiKey": "s3cr3t-input"}, input)
+
+	w := &strings.Builder{}
+	log.L.SetWriter(w)
+	defer log.L.SetWriter(os.Stdout)
+	log.L.WarnWithData("logged", log.Data{"apiKey": input["apiKey"]})
+	require.NotContains(t, w.String(), "s3cr3t-input")
+}
+
+func Test_Variables_secret_resolved(t *testing.T) {
+	vars := newTestVariables(t, `
+variables:
+  - name: apiKey
+    type: string
+    secret: true
+    default: s3cr3t-default
+  - name: token
+    type: string
+    secret: true
+    value: "{{ .apiKey }}-computed"
+`)
+
+	input, err := vars.fromInput(common.InputVariables{})
+	require.Nil(t, err)
+
+	w := &strings.Builder{}
+	log.L.SetWriter(w)
+	defer log.L.SetWriter(os.Stdout)
+	log.L.WarnWithData("logged", log.Data{"apiKey": input["apiKey"], "token": input["token"]})
+	require.NotContains(t, w.String(), "s3cr3t")
+}
+
+const optionalVariablesYAML = `
+variables:
+  - name: name
+    type: string
+  - name: nickname
+    type: string
+    required: false
+  - name: tags
+    type: array
+    required: false
+  - name: anything
+    required: false
+`
+
+func Test_Variables_optional(t *testing.T) {
+	expected := common.InputVariables{"name": "pasta", "nickname": "", "tags": []interface{}{}, "anything": nil}
+	vars := newTestVariables(t, optionalVariablesYAML)
+
+	input, err := vars.fromInput(common.InputVariables{"name": "pasta", "anything": nil})
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	input, err = vars.fromReader(newLineReader(strings.NewReader("\npasta\n\n\n\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, expected, input)
+
+	e, err := newExpression("optional", "{{ if .nickname }}{{ .nickname }}{{ else }}{{ .name }}{{ end }}{{ if .anything }}!{{ end }}")
+	require.Nil(t, err)
+	rendered, err := e.render(input)
+	require.Nil(t, err)
+	require.Equal(t, "pasta", rendered)
+
+	require.Contains(t, vars[1].prompt(), "optional")
+}
+
+func Test_Variables_optional_error(t *testing.T) {
+	vars := newTestVariables(t, optionalVariablesYAML)
+	_, err := vars.fromInput(common.InputVariables{"nickname": "pasta"})
+	require.NotNil(t, err)
+}
+
+const enumVariablesYAML = `
+variables:
+  - name: size
+    type: string
+    enum: [small, medium, large]
+    default: medium
+  - name: replicas
+    type: integer
+    enum: [1, 3, 5]
+`
+
+func Test_Variables_enum(t *testing.T) {
+	vars := newTestVariables(t, enumVariablesYAML)
+
+	input, err := vars.fromReader(newLineReader(strings.NewReader("huge\nlarge\n2\n3\n")), nil)
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"size": "large", "replicas": 3}, input)
+
+	input, err = vars.fromInput(common.InputVariables{"replicas": 5})
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"size": "medium", "replicas": 5}, input)
+
+	_, err = vars.fromInput(common.InputVariables{"replicas": 2})
+	require.NotNil(t, err)
+	require.Equal(t, "value error: `2` is not one of [1, 3, 5]", err.Error())
+
+	require.Contains(t, vars[0].prompt(), "one of [small, medium, large]")
+	require.Equal(t, "input must be one of [small, medium, large]", vars[0].Help())
+}
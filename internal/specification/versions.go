@@ -0,0 +1,111 @@
+package specification
+
+import (
+	"bytes"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// SpecVersion is the latest specification version this cli understands
+const SpecVersion = 3
+
+// decoders decode each specification version, migrating it to the latest one
+var decoders = map[int]func([]byte) (*SpecData, error){
+	1: decodeV1,
+	2: decodeV2,
+	3: decodeLatest,
+}
+
+// decode decodes the specification yaml, rejecting unknown keys
+func decode(raw []byte) (*SpecData, error) {
+	header := struct {
+		Version       int    `yaml:"version"`
+		MinCLIVersion string `yaml:"min-cli-version"`
+	}{}
+	if err := yaml.Unmarshal(raw, &header); err != nil {
+		return nil, fmt.Errorf("specification yaml file error: %s", err.Error())
+	}
+
+	if err := checkCLIVersion(header.MinCLIVersion); err != nil {
+		return nil, err
+	}
+
+	version := header.Version
+	if version == 0 {
+		version = 1
+	}
+	if version > SpecVersion {
+		return nil, fmt.Errorf(
+			"specification version %d is not supported by this copy-basta version (latest supported is %d). "+
+				"please upgrade copy-basta",
+			version, SpecVersion,
+		)
+	}
+
+	decoder, ok := decoders[version]
+	if !ok {
+		return nil, fmt.Errorf("specification error: invalid version %d", version)
+	}
+
+	data, err := decoder(raw)
+	if err != nil {
+		if header.MinCLIVersion != "" && !cliVersionMet(header.MinCLIVersion) {
+			return nil, fmt.Errorf(
+				"specification yaml file error: %s (the template requires copy-basta %s or newer)",
+				err.Error(), header.MinCLIVersion,
+			)
+		}
+		return nil, fmt.Errorf("specification yaml file error: %s", err.Error())
+	}
+	data.Version = SpecVersion
+	return data, nil
+}
+
+func decodeStrict(raw []byte, out interface{}) error {
+	d := yaml.NewDecoder(bytes.NewReader(raw))
+	d.SetStrict(true)
+	return d.Decode(out)
+}
+
+func decodeLatest(raw []byte) (*SpecData, error) {
+	data := SpecData{}
+	if err := decodeStrict(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+func decodeV1(raw []byte) (*SpecData, error) {
+	dataV1 := specDataV1{}
+	if err := decodeStrict(raw, &dataV1); err != nil {
+		return nil, err
+	}
+
+	data := dataV1.SpecData
+	data.PassThrough = append(data.PassThrough, dataV1.PassedThrough...)
+	migrateLegacy(&data)
+	return &data, nil
+}
+
+// decodeV2 decodes the specifications written before the gitignore patterns semantics
+func decodeV2(raw []byte) (*SpecData, error) {
+	data, err := decodeLatest(raw)
+	if err != nil {
+		return nil, err
+	}
+	migrateLegacy(data)
+	return data, nil
+}
+
+// migrateLegacy keeps the behaviour of the specifications before version 3: their patterns have
+// the legacy semantics, their variable names are not nested, and the template ignore files
+// are only honored when they opt in
+func migrateLegacy(data *SpecData) {
+	data.LegacyPatterns = true
+	data.LegacyNames = true
+	if data.HonorIgnoreFiles == nil {
+		honor := false
+		data.HonorIgnoreFiles = &honor
+	}
+}
@@ -1,6 +1,7 @@
 package write
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"path/filepath"
@@ -46,10 +47,38 @@ func Write(destDir string, files []load.File, input common.InputVariables) error
 }
 
 func write(destDir string, files []load.File, input common.InputVariables) error {
+	partials, err := parsePartials(files)
+	if err != nil {
+		return err
+	}
+
 	for _, file := range files {
-		fpath := filepath.Join(destDir, file.Path)
+		if file.Partial != "" {
+			continue
+		}
+
+		rawPath := file.Path
+		if file.To != "" {
+			rawPath = file.To
+		}
+		fpath := filepath.Join(destDir, rawPath)
+
+		scopedInput, err := scoped(input, file.Scope)
+		if err != nil {
+			return err
+		}
 
 		if !file.Template {
+			if file.To != "" {
+				genPath, err := execute(newTemplate("pathTemplate", file.Options), fpath, scopedInput)
+				if err != nil {
+					return err
+				}
+				fpath = *genPath
+			}
+			if err := checkInside(destDir, fpath); err != nil {
+				return err
+			}
 			err := writeFile(fpath, file.Mode, file.Content)
 			if err != nil {
 				return err
@@ -57,10 +86,13 @@ func write(destDir string, files []load.File, input common.InputVariables) error
 			continue
 		}
 
-		genPath, genContent, err := generateFromTemplate(fpath, string(file.Content), input)
+		genPath, genContent, err := generateFromTemplate(fpath, string(file.Content), scopedInput, file.Options, partials[file.Scope])
 		if err != nil {
 			return err
 		}
+		if err := checkInside(destDir, *genPath); err != nil {
+			return err
+		}
 		err = writeFile(*genPath, file.Mode, []byte(*genContent))
 		if err != nil {
 			return err
@@ -69,36 +101,96 @@ func write(destDir string, files []load.File, input common.InputVariables) error
 	return nil
 }
 
+// scoped returns the input variables nested under the scope
+func scoped(input common.InputVariables, scope string) (common.InputVariables, error) {
+	if scope == "" {
+		return input, nil
+	}
+	for _, name := range strings.Split(scope, ".") {
+		nested, ok := input[name].(common.InputVariables)
+		if !ok {
+			return nil, fmt.Errorf("write error: no input variables for scope `%s`", scope)
+		}
+		input = nested
+	}
+	return input, nil
+}
+
+// checkInside checks that the generated path, rendered from the input, is inside the destination directory
+func checkInside(destDir string, fpath string) error {
+	rel, err := filepath.Rel(destDir, filepath.Clean(fpath))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("write error: `%s` is outside the destination directory", fpath)
+	}
+	return nil
+}
+
 func cleanup(destDir string) {
 	if err := os.RemoveAll(destDir); err != nil {
 		log.L.DebugWithData("external error", log.Data{"error": err.Error()})
 	}
 }
 
-func generateFromTemplate(rawPath string, rawContent string, input common.InputVariables) (*string, *string, error) {
-	w := strings.Builder{}
-	pathT, err := newTemplate("pathTemplate").Parse(rawPath)
+// parsePartials parses the partial files once, by scope. each partial is parsed as a template named
+// after its file path, then associated under its partial name, so errors report the partial file and line
+func parsePartials(files []load.File) (map[string]*template.Template, error) {
+	partials := map[string]*template.Template{}
+	for _, file := range files {
+		if file.Partial == "" {
+			continue
+		}
+		t, ok := partials[file.Scope]
+		if !ok {
+			t = common.NewTemplate("partials")
+			partials[file.Scope] = t
+		}
+
+		parsed, err := withOptions(t.New(file.Path), file.Options).Parse(string(file.Content))
+		if err != nil {
+			return nil, err
+		}
+		_, err = t.AddParseTree(file.Partial, parsed.Tree)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return partials, nil
+}
+
+func generateFromTemplate(rawPath string, rawContent string, input common.InputVariables, options load.TemplateOptions, partials *template.Template) (*string, *string, error) {
+	generatedPath, err := execute(newTemplate("pathTemplate", options), rawPath, input)
 	if err != nil {
 		return nil, nil, err
 	}
-	err = pathT.Execute(&w, input)
+
+	contentT := newTemplate("contentTemplate", options)
+	if partials != nil {
+		contentT, err = partials.Clone()
+		if err != nil {
+			return nil, nil, err
+		}
+		contentT = withOptions(contentT.New("contentTemplate"), options)
+	}
+	generatedContent, err := execute(contentT, rawContent, input)
 	if err != nil {
 		return nil, nil, err
 	}
-	generatedPath := w.String()
 
-	w.Reset()
-	contentT, err := newTemplate("contentTemplate").Parse(rawContent)
+	return generatedPath, generatedContent, nil
+}
+
+func execute(t *template.Template, raw string, input common.InputVariables) (*string, error) {
+	w := strings.Builder{}
+	t, err := t.Parse(raw)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	err = contentT.Execute(&w, input)
+	err = t.Execute(&w, input)
 	if err != nil {
-		return nil, nil, err
+		return nil, err
 	}
-	generatedContent := w.String()
-
-	return &generatedPath, &generatedContent, nil
+	generated := w.String()
+	return &generated, nil
 }
 
 func writeFile(fpath string, mode os.FileMode, content []byte) error {
@@ -121,12 +213,16 @@ func writeFile(fpath string, mode os.FileMode, content []byte) error {
 	return nil
 }
 
-func newTemplate(name string) *template.Template {
-	return template.New("t").
-		Option("missingkey=error").
-		Funcs(template.FuncMap{
-			"stringsToUpper": strings.ToUpper,
-			"stringsToLower": strings.ToLower,
-			"stringsTitle":   strings.Title,
-		})
+func newTemplate(name string, options load.TemplateOptions) *template.Template {
+	return withOptions(common.NewTemplate("t"), options)
+}
+
+func withOptions(t *template.Template, options load.TemplateOptions) *template.Template {
+	if len(options.Delimiters) == 2 {
+		t = t.Delims(options.Delimiters[0], options.Delimiters[1])
+	}
+	if options.MissingKey != "" {
+		t = t.Option("missingkey=" + options.MissingKey)
+	}
+	return t
 }
@@ -1,11 +1,15 @@
 package write
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
 
 	"copy-basta/internal/common"
+	"copy-basta/internal/load"
 )
 
 func Test_generateFromTemplate(t *testing.T) {
@@ -39,7 +43,7 @@ func Test_generateFromTemplate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			genPath, genContent, err := generateFromTemplate(tt.rawPath, tt.rawContent, input)
+			genPath, genContent, err := generateFromTemplate(tt.rawPath, tt.rawContent, input, load.TemplateOptions{}, nil)
 			require.Nil(t, err)
 			require.Equal(t, tt.expectedGenPath, *genPath)
 			require.Equal(t, tt.expectedGenContent, *genContent)
@@ -72,8 +76,133 @@ func Test_generateFromTemplate_error(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			_, _, err := generateFromTemplate(tt.rawPath, tt.rawContent, input)
+			_, _, err := generateFromTemplate(tt.rawPath, tt.rawContent, input, load.TemplateOptions{}, nil)
 			require.NotNil(t, err)
 		})
 	}
 }
+
+func Test_scoped(t *testing.T) {
+	input := common.InputVariables{
+		"name": "users",
+		"db": common.InputVariables{
+			"host":  "users-db",
+			"cache": common.InputVariables{"ttl": 60},
+		},
+	}
+
+	scopedInput, err := scoped(input, "")
+	require.Nil(t, err)
+	require.Equal(t, input, scopedInput)
+
+	scopedInput, err = scoped(input, "db.cache")
+	require.Nil(t, err)
+	require.Equal(t, common.InputVariables{"ttl": 60}, scopedInput)
+
+	_, err = scoped(input, "name")
+	require.NotNil(t, err)
+}
+
+func Test_generateFromTemplate_options(t *testing.T) {
+	input := common.InputVariables{
+		"user": "vasco",
+	}
+
+	tests := []struct {
+		name               string
+		rawPath            string
+		rawContent         string
+		options            load.TemplateOptions
+		expectedGenPath    string
+		expectedGenContent string
+	}{
+		{
+			name:               "delimiters",
+			rawPath:            "dir/[[.user]].yml",
+			rawContent:         "name: [[ .user ]]\nref: ${{ github.ref }}",
+			options:            load.TemplateOptions{Delimiters: []string{"[[", "]]"}},
+			expectedGenPath:    "dir/vasco.yml",
+			expectedGenContent: "name: vasco\nref: ${{ github.ref }}",
+		},
+		{
+			name:               "missing key zero",
+			rawPath:            "dir/{{.user}}.txt",
+			rawContent:         "{{ .user }}:{{ .missing }}",
+			options:            load.TemplateOptions{MissingKey: "zero"},
+			expectedGenPath:    "dir/vasco.txt",
+			expectedGenContent: "vasco:<no value>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			genPath, genContent, err := generateFromTemplate(tt.rawPath, tt.rawContent, input, tt.options, nil)
+			require.Nil(t, err)
+			require.Equal(t, tt.expectedGenPath, *genPath)
+			require.Equal(t, tt.expectedGenContent, *genContent)
+		})
+	}
+}
+
+func Test_generateFromTemplate_partials(t *testing.T) {
+	files := []load.File{
+		{Path: "partials/header.tmpl", Partial: "header", Content: []byte("// Copyright {{ .user }}\n")},
+		{Path: "partials/license/mit.txt", Partial: "license/mit", Content: []byte("MIT")},
+		{Path: "partials/broken.tmpl", Partial: "broken", Content: []byte("line 1\n{{ .missing }}")},
+	}
+	partials, err := parsePartials(files)
+	require.Nil(t, err)
+
+	input := common.InputVariables{"user": "vasco"}
+
+	_, genContent, err := generateFromTemplate("main.go", "{{ template \"header\" . }}package main // {{ template \"license/mit\" }}", input, load.TemplateOptions{}, partials[""])
+	require.Nil(t, err)
+	require.Equal(t, "// Copyright vasco\npackage main // MIT", *genContent)
+
+	_, _, err = generateFromTemplate("main.go", "{{ template \"broken\" . }}", input, load.TemplateOptions{}, partials[""])
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "partials/broken.tmpl:2")
+}
+
+func Test_parsePartials_error(t *testing.T) {
+	files := []load.File{
+		{Path: "partials/header.tmpl", Partial: "header", Content: []byte("line 1\n{{ .user ")},
+	}
+	_, err := parsePartials(files)
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "partials/header.tmpl:2")
+}
+
+func Test_write_outsideDest(t *testing.T) {
+	root, err := ioutil.TempDir("", "write")
+	require.Nil(t, err)
+	defer func() { _ = os.RemoveAll(root) }()
+	dest := filepath.Join(root, "dest")
+
+	tests := []struct {
+		name string
+		file load.File
+	}{
+		{
+			name: "rendered to",
+			file: load.File{Path: "config.yml", To: "{{ .dir }}/config.yml", Mode: 0644, Template: true},
+		},
+		{
+			name: "rendered pass-through to",
+			file: load.File{Path: "config.yml", To: "{{ .dir }}/config.yml", Mode: 0644},
+		},
+		{
+			name: "rendered path",
+			file: load.File{Path: "{{ .dir }}/config.yml", Mode: 0644, Template: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Write(dest, []load.File{tt.file}, common.InputVariables{"dir": "../.."})
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), "is outside the destination directory")
+			_, err = os.Stat(filepath.Join(root, "..", "config.yml"))
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
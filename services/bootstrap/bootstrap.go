@@ -113,6 +113,8 @@ _--src_ should be the directory containing this file
 You should override this file with information that is relevant for your template!
 `
 	specText = `---
+version: 3
+
 ignore:
   - .git/
   - readme.md
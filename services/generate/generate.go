@@ -4,10 +4,10 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 
-	"copy-basta/internal/clients/github"
 	"copy-basta/internal/common"
 	"copy-basta/internal/common/log"
 	"copy-basta/internal/crawl"
@@ -21,15 +21,24 @@ type Params struct {
 	Dest      string
 	SpecYAML  string
 	InputYAML string
+	Preset    string
+	// Lang is the language of the prompts, the locale one when empty
+	Lang      string
 	Overwrite bool
+	// CLIVersion is checked against the minimum version required by the template, unless SkipVersionCheck
+	CLIVersion       string
+	SkipVersionCheck bool
 }
 
 func Generate(params *Params) error {
 	log.L.DebugWithData("params", log.Data{
-		"src":       params.Src,
-		"dest":      params.Dest,
-		"specYAML":  params.SpecYAML,
-		"inputYAML": params.InputYAML,
+		"src":        params.Src,
+		"dest":       params.Dest,
+		"specYAML":   params.SpecYAML,
+		"inputYAML":  params.InputYAML,
+		"preset":     params.Preset,
+		"lang":       params.Lang,
+		"cliVersion": params.CLIVersion,
 	})
 
 	log.L.Info("validating params...")
@@ -40,7 +49,7 @@ func Generate(params *Params) error {
 	log.L.Info("params are valid!")
 
 	log.L.Info("crawling files...")
-	crawler, err := getCrawler(params.Src)
+	crawler, err := crawl.New(params.Src)
 	if err != nil {
 		return err
 	}
@@ -51,59 +60,141 @@ func Generate(params *Params) error {
 	log.L.Info("files crawled!")
 
 	log.L.Info("loading specification...")
-	specLoadedPath := common.TrimRootDir(filepath.Join(params.Src, params.SpecYAML))
-	spec, err := specification.New(specLoadedPath, crawledFiles, params.Overwrite)
+	if params.SkipVersionCheck {
+		log.L.Warn("skipping the cli version check...")
+	} else {
+		// the minimum cli version is checked as each specification is decoded
+		specification.SetCLIVersion(params.CLIVersion)
+	}
+	specLoadedPath := filepath.ToSlash(filepath.Clean(params.SpecYAML))
+	specData, err := specification.Load(specLoadedPath, crawledFiles)
 	if err != nil {
 		return err
 	}
-	log.L.Info("spec loaded!")
-
-	log.L.Info("loading files...")
-	loader, err := load.New(spec.Ignorer, spec.Passer)
+	crawledFiles, specData, err = specification.Extend(params.Src, crawledFiles, specData)
 	if err != nil {
 		return err
 	}
-	files, err := loader.Load(crawledFiles)
+	spec, err := specification.New(specData, params.Overwrite)
 	if err != nil {
 		return err
 	}
-	{
-		logData := log.Data{}
-		for _, f := range files {
-			logData[f.Path] = fmt.Sprintf("mode=%v, is-template=%T, byte-counts=%d", f.Mode, f.Template, len(f.Content))
-		}
-		log.L.DebugWithData("loaded files", logData)
+	err = spec.HonorIgnoreFiles(crawledFiles)
+	if err != nil {
+		return err
+	}
+	err = spec.LoadData(crawledFiles)
+	if err != nil {
+		return err
+	}
+	err = spec.LoadComponents(params.Src, params.Overwrite)
+	if err != nil {
+		return err
+	}
+	err = spec.CheckFiles(crawledFiles)
+	if err != nil {
+		return err
+	}
+	spec.SetLanguage(common.Language(params.Lang))
+	log.L.Info("spec loaded!")
+	if spec.Metadata != nil {
+		fmt.Print("\n" + spec.Metadata.String())
 	}
-	log.L.Info("files loaded!")
+
+	log.L.Info("checking requirements...")
+	err = spec.CheckRequirements()
+	if err != nil {
+		return err
+	}
+	log.L.Info("requirements are met!")
 
 	var input common.InputVariables
 	if params.InputYAML != "" {
 		log.L.InfoWithData("loading template variables from file", log.Data{"location": params.InputYAML})
-		fileInput, err := spec.Variables.InputFromFile(params.InputYAML)
+		fileInput, err := spec.InputFromFile(params.InputYAML, params.Preset)
 		if err != nil {
 			return err
 		}
 		input = fileInput
 	} else {
 		log.L.Info("getting template variables dynamically")
-		stdinInput, err := spec.Variables.InputFromStdIn()
+		stdinInput, err := spec.InputFromStdIn(params.Preset)
 		if err != nil {
 			return err
 		}
 		input = stdinInput
 	}
 
+	log.L.Info("loading files...")
+	files, err := loadFiles(spec, crawledFiles, input, "", "")
+	if err != nil {
+		return err
+	}
+	{
+		logData := log.Data{}
+		for _, f := range files {
+			logData[f.Path] = fmt.Sprintf("mode=%v, is-template=%T, byte-counts=%d", f.Mode, f.Template, len(f.Content))
+		}
+		log.L.DebugWithData("loaded files", logData)
+	}
+	log.L.Info("files loaded!")
+
 	log.L.InfoWithData("writing to new project", log.Data{"location": params.Dest})
 	writer := write.NewDiskWriter(params.Dest)
-	err = writer.Write(files, input)
+	err = writer.Write(files, spec.TemplateInput(input))
 	if err != nil {
 		return err
 	}
 
+	message, err := spec.Message(input)
+	if err != nil {
+		log.L.Warn(err.Error())
+	} else if message != "" {
+		fmt.Println(message)
+	}
+
 	log.L.Info("done!")
 	return nil
 }
 
+// loadFiles loads the template files included by the input and the ones of its components,
+// mounting each component in its destination subpath
+func loadFiles(spec *specification.Spec, crawledFiles []crawl.File, input common.InputVariables, scope string, dest string) ([]load.File, error) {
+	ignorer, err := spec.FilesIgnorer(input)
+	if err != nil {
+		return nil, err
+	}
+	loader, err := load.New(ignorer, spec.Passer, spec.Ruler)
+	if err != nil {
+		return nil, err
+	}
+	files, err := loader.Load(crawledFiles)
+	if err != nil {
+		return nil, err
+	}
+	for i := range files {
+		files[i].Path = path.Join(dest, files[i].Path)
+		if files[i].To != "" {
+			files[i].To = path.Join(dest, files[i].To)
+		}
+		files[i].Scope = scope
+	}
+
+	for _, c := range spec.Components {
+		componentScope := c.Name
+		if scope != "" {
+			componentScope = scope + "." + c.Name
+		}
+		componentInput, _ := input[c.Name].(common.InputVariables)
+		componentFiles, err := loadFiles(c.Spec, c.Files, componentInput, componentScope, path.Join(dest, c.Dest))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, componentFiles...)
+	}
+	return files, nil
+}
+
 func validate(params *Params) error {
 	if params.Src == "" {
 		return errors.New("params validation error - src can't be empty")
@@ -197,18 +288,3 @@ func validateInputYAML(inputYAML string) error {
 	}
 	return nil
 }
-
-func getCrawler(src string) (crawl.Crawler, error) {
-	switch {
-	case strings.HasPrefix(src, common.GithubPrefix):
-		log.L.Debug("using github crawler")
-		ghc, err := github.NewClient(strings.TrimPrefix(src, common.GithubPrefix))
-		if err != nil {
-			return nil, err
-		}
-		return crawl.NewGithubCrawler(ghc), nil
-	default:
-		log.L.Debug("using disk crawler")
-		return crawl.NewLocalCrawler(src), nil
-	}
-}